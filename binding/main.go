@@ -36,6 +36,10 @@ typedef struct {
     Vector Anchor;       // Anchor position relative to the object's center
     double GravityFactor; // Gravity factor
     Impulse* Impulses;   // Linked list of active impulses
+    uint8_t Bullet;      // Continuous collision detection (1) or not (0)
+    double Restitution;  // Bounciness (0 = stops dead, 1 = bounces back)
+    double Friction;     // Ground friction, horizontal velocity decay rate (1/s)
+    double Drag;         // Linear air drag, velocity decay rate (1/s)
 } Object;
 
 typedef struct {
@@ -45,22 +49,129 @@ typedef struct {
     int32_t ObjectCount;
 } World;
 
+typedef enum {
+    ContactBegin,  // Two objects started touching
+    ContactEnd,    // Two objects stopped touching
+    Landed,        // Object came to rest on the floor or another object
+    LeftBounds,    // Object left the world boundaries
+    ObjectAdded,   // Object added to the world
+    ObjectRemoved  // Object removed from the world
+} EventType;
+
+typedef struct {
+    EventType Type;    // Type of the event
+    uint64_t Tick;     // Tick number when the event happened
+    int32_t ObjectID;  // Object the event is about
+    int32_t OtherID;   // Other object of the contact or the landing
+    uint8_t HasOther;  // OtherID is set (1) or not (0)
+    Vector Normal;     // Contact normal pointing towards the object
+    Vector Position;   // Object position when the event happened
+} Event;
+
+typedef struct {
+    int32_t ObjectID;  // ID of the hit object
+    Vector Point;      // Hit point (box center at the moment of impact for box casts)
+    Vector Normal;     // Surface normal of the hit object
+    double Distance;   // Distance travelled before the hit
+} RaycastHit;
+
+typedef enum {
+    RunOK,             // Started, stopped by Stop or never started
+    RunRunning,        // The update loop is running
+    RunInvalidTick,    // tickMS is not positive and finite
+    RunNoWorld,        // There is no world to update
+    RunAlreadyRunning, // The update loop is already running
+    RunFailed          // The update loop stopped by itself, e.g. the update or an event handler panicked
+} RunStatus;
+
+typedef enum {
+    CommandMove,    // Set the velocity of the object
+    CommandJump,    // Set the vertical speed of the object standing on something
+    CommandImpulse, // Add an impulse to the object
+    CommandSpawn,   // Add the object to the world
+    CommandRemove   // Remove the object from the world
+} CommandKind;
+
+// Opaque handle of an engine instance, 0 is never a valid handle
+typedef int32_t EngineHandle;
+
+// Tick hook called inside the engine lock at every tick with the tick number and its duration in seconds
+// The hook may change the world only with the Tick* functions, other engine functions would deadlock
+typedef void (*TickHook)(EngineHandle handle, uint64_t tick, double elapsed, void* userData);
+
+// Call the tick hook, Go can't call C function pointers directly
+static inline void callTickHook(TickHook hook, EngineHandle handle, uint64_t tick, double elapsed, void* userData) {
+    hook(handle, tick, elapsed, userData);
+}
+
+// Command authorizer called inside the engine lock for every command of the batch, NULL allows every command
+// with the ID, type and client flag of the target object (the spawned object for spawn commands)
+// Returns 1 to allow the command, other engine functions would deadlock
+typedef uint8_t (*CommandAuthorizer)(EngineHandle handle, uint32_t clientID, CommandKind kind, int32_t objectID, ObjectType type, uint8_t client, void* userData);
+
+// Call the command authorizer, Go can't call C function pointers directly
+static inline uint8_t callCommandAuthorizer(CommandAuthorizer authorize, EngineHandle handle, uint32_t clientID, CommandKind kind, int32_t objectID, ObjectType type, uint8_t client, void* userData) {
+    return authorize(handle, clientID, kind, objectID, type, client, userData);
+}
+
 // Forward declarations for exporting
-void CreateWorld(double gravity, Vector boundary);
-void SetWorld(World* world, double rtt);
-void Run(double tickMS);
-void Stop();
-World* GetWorldPtr();
-uint8_t* GetWorldBytes(int32_t* size);
-Object* GetObjectPtr(int32_t id);
-void UpsertObject(Object* obj);
-void UpsertObjects(Object* objects, int32_t count);
-void AddImpulse(int32_t id, Vector direction, double damping);
-void SetVelocity(int32_t id, Vector velocity);
-void SetPosition(int32_t id, Vector position);
-void SetAnchor(int32_t id, Vector anchor);
-void RemoveObject(int32_t id);
-void RemoveObjects(int32_t* ids, int32_t count);
+EngineHandle EngineCreate();
+void EngineDestroy(EngineHandle handle);
+void CreateWorld(EngineHandle handle, double gravity, Vector boundary);
+void SetWorld(EngineHandle handle, World* world, double rtt);
+void Reconcile(EngineHandle handle, World* world, uint32_t lastAck);
+uint32_t PredictImpulse(EngineHandle handle, int32_t id, Vector direction, double damping);
+uint32_t PredictVelocity(EngineHandle handle, int32_t id, Vector velocity);
+void SetInterpolation(EngineHandle handle, double delay, int32_t bufferSize, double maxExtrapolation);
+void PushSnapshot(EngineHandle handle, World* world, double serverTime);
+uint8_t ApplyCommands(EngineHandle handle, uint32_t clientID, uint8_t* data, int32_t size, CommandAuthorizer authorize, void* userData, uint32_t* lastSequence);
+void ResetCommands(EngineHandle handle, uint32_t clientID);
+uint8_t GetRemotePosition(EngineHandle handle, int32_t id, double renderTime, Vector* position);
+RunStatus Run(EngineHandle handle, double tickMS);
+RunStatus GetRunStatus(EngineHandle handle, char* message, int32_t capacity);
+void Stop(EngineHandle handle);
+void Pause(EngineHandle handle);
+void Resume(EngineHandle handle);
+uint8_t IsPaused(EngineHandle handle);
+void SetTimeScale(EngineHandle handle, double scale);
+double GetTimeScale(EngineHandle handle);
+void SetMaxElapsed(EngineHandle handle, double maxMS);
+uint64_t Step(EngineHandle handle, double dt);
+uint64_t GetTick(EngineHandle handle);
+double GetTime(EngineHandle handle);
+void SetFixedStep(EngineHandle handle, double stepMS, int32_t maxSubsteps);
+void SetWorkers(EngineHandle handle, int32_t workers);
+double GetInterpolationAlpha(EngineHandle handle);
+void SetEventQueueSize(EngineHandle handle, int32_t capacity);
+int32_t PollEvents(EngineHandle handle, Event* events, int32_t capacity);
+uint8_t Raycast(EngineHandle handle, Vector origin, Vector direction, double maxDistance, uint32_t typeMask, int32_t ignoreID, RaycastHit* hit);
+uint8_t SegmentCast(EngineHandle handle, Vector from, Vector to, uint32_t typeMask, int32_t ignoreID, RaycastHit* hit);
+uint8_t BoxCast(EngineHandle handle, Vector origin, Vector size, Vector direction, double maxDistance, uint32_t typeMask, int32_t ignoreID, RaycastHit* hit);
+void SetHistorySize(EngineHandle handle, int32_t ticks);
+uint8_t RewindRaycast(EngineHandle handle, double time, Vector origin, Vector direction, double maxDistance, uint32_t typeMask, int32_t ignoreID, RaycastHit* hit);
+int32_t QueryRect(EngineHandle handle, Vector min, Vector max, uint32_t typeMask, int32_t* ids, int32_t capacity);
+int32_t QueryRadius(EngineHandle handle, Vector center, double radius, uint32_t typeMask, int32_t* ids, int32_t capacity);
+int32_t QueryNearest(EngineHandle handle, Vector center, int32_t k, uint32_t typeMask, int32_t* ids, int32_t capacity);
+World* GetWorldPtr(EngineHandle handle);
+uint8_t* GetWorldBytes(EngineHandle handle, int32_t* size);
+Object* GetObjectPtr(EngineHandle handle, int32_t id);
+void UpsertObject(EngineHandle handle, Object* obj);
+void UpsertObjects(EngineHandle handle, Object* objects, int32_t count);
+void AddImpulse(EngineHandle handle, int32_t id, Vector direction, double damping);
+void SetVelocity(EngineHandle handle, int32_t id, Vector velocity);
+void SetPosition(EngineHandle handle, int32_t id, Vector position);
+void SetAnchor(EngineHandle handle, int32_t id, Vector anchor);
+void RemoveObject(EngineHandle handle, int32_t id);
+void RemoveObjects(EngineHandle handle, int32_t* ids, int32_t count);
+uint32_t AddPreTickHook(EngineHandle handle, TickHook hook, void* userData);
+uint32_t AddPostTickHook(EngineHandle handle, TickHook hook, void* userData);
+void RemoveTickHook(uint32_t id);
+Object* TickGetObjectPtr(EngineHandle handle, int32_t id);
+void TickUpsertObject(EngineHandle handle, Object* obj);
+void TickRemoveObject(EngineHandle handle, int32_t id);
+void TickAddImpulse(EngineHandle handle, int32_t id, Vector direction, double damping);
+void TickSetVelocity(EngineHandle handle, int32_t id, Vector velocity);
+void TickSetPosition(EngineHandle handle, int32_t id, Vector position);
 void FreeImpulsePtr(Impulse* impulse);
 void FreeObjectPtr(Object* obj);
 void FreeWorldPtr(World* world);
@@ -68,45 +179,472 @@ void FreeWorldPtr(World* world);
 import "C"
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"unsafe"
 
 	"github.com/plugfox/slash-engine-go/engine"
 )
 
+// Engine instances of the C API by their handles
+//
 //nolint:gochecknoglobals
-var singleton = &engine.Engine{} // Create a global instance of Engine for the C API
+var (
+	engines      = make(map[C.EngineHandle]*engine.Engine)
+	enginesMutex sync.RWMutex
+	lastHandle   C.EngineHandle
+)
+
+// Tick hooks registered from C and the worlds of the hooks being called
+//
+//nolint:gochecknoglobals
+var (
+	tickHooks  = make(map[C.uint32_t]tickHook)              // Registered hooks by their ids
+	tickWorlds = make(map[C.EngineHandle]*engine.TickWorld) // Worlds of the running hooks by the engine handles
+	hooksMutex sync.Mutex
+	lastHookID C.uint32_t
+)
+
+// Tick hook registered from C
+type tickHook struct {
+	handle C.EngineHandle // Engine the hook is registered with
+	remove func()         // Function removing the hook from the engine
+}
+
+//export EngineCreate
+func EngineCreate() C.EngineHandle {
+	enginesMutex.Lock()
+	defer enginesMutex.Unlock()
+	lastHandle++
+	engines[lastHandle] = &engine.Engine{}
+	return lastHandle
+}
+
+// Destroy the engine, its update loop and workers are stopped and its tick hooks are removed
+
+//export EngineDestroy
+func EngineDestroy(handle C.EngineHandle) {
+	enginesMutex.Lock()
+	eng := engines[handle]
+	delete(engines, handle)
+	enginesMutex.Unlock()
+	if eng != nil {
+		eng.Stop()        // Stop the update loop of the destroyed engine
+		eng.SetWorkers(0) // and its integration workers
+	}
+	_removeTickHooks(handle)
+}
 
 //export CreateWorld
-func CreateWorld(gravity C.double, boundary C.Vector) {
+func CreateWorld(handle C.EngineHandle, gravity C.double, boundary C.Vector) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goBoundary := engine.Vector{X: float64(boundary.X), Y: float64(boundary.Y)}
-	singleton.CreateWorld(float64(gravity), goBoundary)
+	eng.CreateWorld(float64(gravity), goBoundary)
 }
 
 //export SetWorld
-func SetWorld(world *C.World, rtt C.double) {
+func SetWorld(handle C.EngineHandle, world *C.World, rtt C.double) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goRTT := float64(rtt)
 	if world == nil {
-		singleton.SetWorld(nil, goRTT)
+		eng.SetWorld(nil, goRTT)
 		return
 	}
 	goWorld := _convertWorldToGo(world) // Преобразуем C-мир в Go-мир
-	singleton.SetWorld(goWorld, goRTT)  // Устанавливаем преобразованный мир в движок
+	eng.SetWorld(goWorld, goRTT)        // Устанавливаем преобразованный мир в движок
 }
 
+//export Reconcile
+func Reconcile(handle C.EngineHandle, world *C.World, lastAck C.uint32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	if world == nil {
+		eng.Reconcile(nil, uint32(lastAck))
+		return
+	}
+	eng.Reconcile(_convertWorldToGo(world), uint32(lastAck))
+}
+
+//export PredictImpulse
+func PredictImpulse(handle C.EngineHandle, id C.int32_t, direction C.Vector, damping C.double) C.uint32_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return C.uint32_t(eng.PredictImpulse(int(id), _convertVectorToGo(direction), float64(damping)))
+}
+
+//export PredictVelocity
+func PredictVelocity(handle C.EngineHandle, id C.int32_t, velocity C.Vector) C.uint32_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return C.uint32_t(eng.PredictVelocity(int(id), _convertVectorToGo(velocity)))
+}
+
+//export SetInterpolation
+func SetInterpolation(handle C.EngineHandle, delay C.double, bufferSize C.int32_t, maxExtrapolation C.double) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.SetInterpolation(float64(delay), int(bufferSize), float64(maxExtrapolation))
+}
+
+//export PushSnapshot
+func PushSnapshot(handle C.EngineHandle, world *C.World, serverTime C.double) {
+	eng := _getEngine(handle)
+	if eng == nil || world == nil {
+		return
+	}
+	eng.PushSnapshot(_convertWorldToGo(world), float64(serverTime))
+}
+
+// Decode and apply a FlatBuffers CommandBatch of the client atomically
+// Every command must be allowed by the authorizer, NULL allows every command:
+// pass NULL only for the trusted batches, e.g. made by the host itself,
+// and an authorizer checking the client (e.g. owns the target object) for the batches received from the clients
+// Returns 1 and writes the last command sequence on success,
+// 0 if the batch was rejected, e.g. stale, replayed or unauthorized
+
+//export ApplyCommands
+func ApplyCommands(handle C.EngineHandle, clientID C.uint32_t, data *C.uint8_t, size C.int32_t, authorize C.CommandAuthorizer, userData unsafe.Pointer, lastSequence *C.uint32_t) C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil || data == nil || size <= 0 {
+		return 0
+	}
+	authorizer := engine.AllowAllCommands
+	if authorize != nil {
+		authorizer = func(clientID uint32, command *engine.Command, target *engine.Object) bool {
+			return C.callCommandAuthorizer(authorize, handle, C.uint32_t(clientID), C.CommandKind(command.Kind),
+				C.int32_t(target.ID), C.ObjectType(target.Type), _boolToUint8(target.Client), userData) != 0
+		}
+	}
+	last, err := eng.ApplyCommands(uint32(clientID), C.GoBytes(unsafe.Pointer(data), C.int(size)), authorizer)
+	if err != nil {
+		return 0
+	}
+	if lastSequence != nil {
+		*lastSequence = C.uint32_t(last)
+	}
+	return 1
+}
+
+//export ResetCommands
+func ResetCommands(handle C.EngineHandle, clientID C.uint32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.ResetCommands(uint32(clientID))
+}
+
+//export GetRemotePosition
+func GetRemotePosition(handle C.EngineHandle, id C.int32_t, renderTime C.double, position *C.Vector) C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	goPosition, ok := eng.GetRemotePosition(int(id), float64(renderTime))
+	if !ok {
+		return 0
+	}
+	if position != nil {
+		*position = _convertVectorToC(goPosition)
+	}
+	return 1
+}
+
+// Start the update loop, returns RunOK or the reason it can't be started
+// Panics never cross the C boundary, a panic in the loop stops it, see GetRunStatus
+
 //export Run
-func Run(tickMS C.double) {
-	singleton.Run(float64(tickMS))
+func Run(handle C.EngineHandle, tickMS C.double) C.RunStatus {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return C.RunNoWorld
+	}
+	return _runStatus(eng.RunContext(context.Background(), float64(tickMS)))
+}
+
+// Get the status of the update loop, RunFailed if it stopped by itself
+// The reason, e.g. the panic with its stack trace, is written into the message buffer
+// of the capacity as a NUL-terminated string, truncated to fit, the buffer can be NULL
+
+//export GetRunStatus
+func GetRunStatus(handle C.EngineHandle, message *C.char, capacity C.int32_t) C.RunStatus {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return C.RunOK
+	}
+	select {
+	case <-eng.Done():
+	default:
+		return C.RunRunning
+	}
+	err := eng.Err()
+	if err != nil && message != nil && capacity > 0 {
+		buffer := unsafe.Slice((*byte)(unsafe.Pointer(message)), int(capacity))
+		buffer[copy(buffer[:capacity-1], err.Error())] = 0
+	}
+	return _runStatus(err)
 }
 
 //export Stop
-func Stop() {
-	singleton.Stop()
+func Stop(handle C.EngineHandle) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.Stop()
+}
+
+//export Pause
+func Pause(handle C.EngineHandle) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.Pause()
+}
+
+//export Resume
+func Resume(handle C.EngineHandle) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.Resume()
+}
+
+//export IsPaused
+func IsPaused(handle C.EngineHandle) C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return _boolToUint8(eng.IsPaused())
+}
+
+//export SetTimeScale
+func SetTimeScale(handle C.EngineHandle, scale C.double) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.SetTimeScale(float64(scale))
+}
+
+//export GetTimeScale
+func GetTimeScale(handle C.EngineHandle) C.double {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return C.double(eng.GetTimeScale())
+}
+
+//export SetMaxElapsed
+func SetMaxElapsed(handle C.EngineHandle, maxMS C.double) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.SetMaxElapsed(float64(maxMS))
+}
+
+//export Step
+func Step(handle C.EngineHandle, dt C.double) C.uint64_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return C.uint64_t(eng.Step(float64(dt)))
+}
+
+//export GetTick
+func GetTick(handle C.EngineHandle) C.uint64_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return C.uint64_t(eng.GetTick())
+}
+
+//export GetTime
+func GetTime(handle C.EngineHandle) C.double {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return C.double(eng.GetTime())
+}
+
+//export SetFixedStep
+func SetFixedStep(handle C.EngineHandle, stepMS C.double, maxSubsteps C.int32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.SetFixedStep(float64(stepMS), int(maxSubsteps))
+}
+
+//export SetWorkers
+func SetWorkers(handle C.EngineHandle, workers C.int32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.SetWorkers(int(workers))
+}
+
+//export GetInterpolationAlpha
+func GetInterpolationAlpha(handle C.EngineHandle) C.double {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	return C.double(eng.GetInterpolationAlpha())
+}
+
+// The event queue is disabled by default, call it before PollEvents, zero or negative capacity disables it
+
+//export SetEventQueueSize
+func SetEventQueueSize(handle C.EngineHandle, capacity C.int32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.SetEventQueueSize(int(capacity))
+}
+
+//export PollEvents
+func PollEvents(handle C.EngineHandle, events *C.Event, capacity C.int32_t) C.int32_t {
+	eng := _getEngine(handle)
+	if eng == nil || events == nil || capacity <= 0 {
+		return 0
+	}
+	buf := make([]engine.Event, int(capacity))
+	n := eng.PollEvents(buf)
+	cEvents := unsafe.Slice(events, int(capacity))
+	for i, event := range buf[:n] {
+		cEvents[i] = C.Event{
+			Type:     C.EventType(event.Type),
+			Tick:     C.uint64_t(event.Tick),
+			ObjectID: C.int32_t(event.ObjectID),
+			OtherID:  C.int32_t(event.OtherID),
+			HasOther: _boolToUint8(event.HasOther),
+			Normal:   C.Vector{X: C.double(event.Normal.X), Y: C.double(event.Normal.Y)},
+			Position: C.Vector{X: C.double(event.Position.X), Y: C.double(event.Position.Y)},
+		}
+	}
+	return C.int32_t(n)
+}
+
+// The casts below take a bitmask of the object types to include (1 << ObjectType, 0 for all types)
+// and an object ID to ignore (e.g. the shooter, -1 to ignore nothing), the hit is written only if 1 is returned
+
+//export Raycast
+func Raycast(handle C.EngineHandle, origin C.Vector, direction C.Vector, maxDistance C.double, typeMask C.uint32_t, ignoreID C.int32_t, hit *C.RaycastHit) C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	result, ok := eng.Raycast(_convertVectorToGo(origin), _convertVectorToGo(direction), float64(maxDistance), _castFilter(typeMask, ignoreID))
+	return _writeRaycastHit(result, ok, hit)
+}
+
+//export SegmentCast
+func SegmentCast(handle C.EngineHandle, from C.Vector, to C.Vector, typeMask C.uint32_t, ignoreID C.int32_t, hit *C.RaycastHit) C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	result, ok := eng.SegmentCast(_convertVectorToGo(from), _convertVectorToGo(to), _castFilter(typeMask, ignoreID))
+	return _writeRaycastHit(result, ok, hit)
+}
+
+//export BoxCast
+func BoxCast(handle C.EngineHandle, origin C.Vector, size C.Vector, direction C.Vector, maxDistance C.double, typeMask C.uint32_t, ignoreID C.int32_t, hit *C.RaycastHit) C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	result, ok := eng.BoxCast(_convertVectorToGo(origin), _convertVectorToGo(size), _convertVectorToGo(direction), float64(maxDistance), _castFilter(typeMask, ignoreID))
+	return _writeRaycastHit(result, ok, hit)
+}
+
+//export SetHistorySize
+func SetHistorySize(handle C.EngineHandle, ticks C.int32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.SetHistorySize(int(ticks))
+}
+
+//export RewindRaycast
+func RewindRaycast(handle C.EngineHandle, time C.double, origin C.Vector, direction C.Vector, maxDistance C.double, typeMask C.uint32_t, ignoreID C.int32_t, hit *C.RaycastHit) C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	result, ok := eng.RewindRaycast(float64(time), _convertVectorToGo(origin), _convertVectorToGo(direction), float64(maxDistance), _castFilter(typeMask, ignoreID))
+	return _writeRaycastHit(result, ok, hit)
+}
+
+// The area queries below write up to capacity IDs sorted in ascending order
+// and return the total number of objects found
+
+//export QueryRect
+func QueryRect(handle C.EngineHandle, minCorner C.Vector, maxCorner C.Vector, typeMask C.uint32_t, ids *C.int32_t, capacity C.int32_t) C.int32_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	objects := eng.QueryRect(_convertVectorToGo(minCorner), _convertVectorToGo(maxCorner), _queryFilter(typeMask))
+	return _writeObjectIDs(objects, ids, capacity)
+}
+
+//export QueryRadius
+func QueryRadius(handle C.EngineHandle, center C.Vector, radius C.double, typeMask C.uint32_t, ids *C.int32_t, capacity C.int32_t) C.int32_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	objects := eng.QueryRadius(_convertVectorToGo(center), float64(radius), _queryFilter(typeMask))
+	return _writeObjectIDs(objects, ids, capacity)
+}
+
+//export QueryNearest
+func QueryNearest(handle C.EngineHandle, center C.Vector, k C.int32_t, typeMask C.uint32_t, ids *C.int32_t, capacity C.int32_t) C.int32_t {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return 0
+	}
+	objects := eng.QueryNearest(_convertVectorToGo(center), int(k), _queryFilter(typeMask))
+	return _writeObjectIDs(objects, ids, capacity)
 }
 
 //export GetWorldPtr
-func GetWorldPtr() *C.World {
-	// Получаем указатель на текущий мир
-	world := singleton.GetWorld()
+func GetWorldPtr(handle C.EngineHandle) *C.World {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return nil
+	}
+
+	// Получаем общий снимок текущего мира, он только читается, пока мир обновляется
+	world := eng.GetWorldView()
 	if world == nil {
 		return nil
 	}
@@ -116,19 +654,24 @@ func GetWorldPtr() *C.World {
 }
 
 //export GetWorldBytes
-func GetWorldBytes(size *C.int32_t) *C.uint8_t {
-	// Получаем объект world
-	world := singleton.GetWorld()
-	if world == nil {
-		// Если world равен nil, возвращаем null
+func GetWorldBytes(handle C.EngineHandle, size *C.int32_t) *C.uint8_t {
+	eng := _getEngine(handle)
+	if eng == nil {
 		if size != nil {
 			*size = 0
 		}
 		return nil
 	}
 
-	// Сериализуем мир в байты
-	data := world.ToBytes()
+	// Сериализуем мир в байты вместе с заголовком (тик и время сервера)
+	data := eng.GetWorldBytes()
+	if data == nil {
+		// Если мира нет, возвращаем null
+		if size != nil {
+			*size = 0
+		}
+		return nil
+	}
 	dataSize := len(data)
 
 	// Выделяем память для массива байт
@@ -152,64 +695,183 @@ func GetWorldBytes(size *C.int32_t) *C.uint8_t {
 }
 
 //export GetObjectPtr
-func GetObjectPtr(id C.int32_t) *C.Object {
-	goObj := singleton.GetObject(int(id))
+func GetObjectPtr(handle C.EngineHandle, id C.int32_t) *C.Object {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return nil
+	}
+	goObj := eng.GetObject(int(id)) // Копия объекта
 	return _convertObjectToC(goObj)
 }
 
 //export UpsertObject
-func UpsertObject(obj *C.Object) {
+func UpsertObject(handle C.EngineHandle, obj *C.Object) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goObj := _convertObjectToGo(obj)
-	singleton.UpsertObject(goObj)
+	eng.UpsertObject(goObj)
 }
 
 //export UpsertObjects
-func UpsertObjects(objects *C.Object, count C.int32_t) {
+func UpsertObjects(handle C.EngineHandle, objects *C.Object, count C.int32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goObjects := make([]*engine.Object, count)
 	objSlice := (*[1 << 30]C.Object)(unsafe.Pointer(objects))[:count:count]
 	for i, obj := range objSlice {
 		goObjects[i] = _convertObjectToGo(&obj)
 	}
-	singleton.UpsertObjects(goObjects)
+	eng.UpsertObjects(goObjects)
 }
 
 //export AddImpulse
-func AddImpulse(id C.int32_t, direction C.Vector, damping C.double) {
+func AddImpulse(handle C.EngineHandle, id C.int32_t, direction C.Vector, damping C.double) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goDirection := engine.Vector{X: float64(direction.X), Y: float64(direction.Y)}
-	singleton.AddImpulse(int(id), goDirection, float64(damping))
+	eng.AddImpulse(int(id), goDirection, float64(damping))
 }
 
 //export SetVelocity
-func SetVelocity(id C.int32_t, velocity C.Vector) {
+func SetVelocity(handle C.EngineHandle, id C.int32_t, velocity C.Vector) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goVelocity := engine.Vector{X: float64(velocity.X), Y: float64(velocity.Y)}
-	singleton.SetVelocity(int(id), goVelocity)
+	eng.SetVelocity(int(id), goVelocity)
 }
 
 //export SetPosition
-func SetPosition(id C.int32_t, position C.Vector) {
+func SetPosition(handle C.EngineHandle, id C.int32_t, position C.Vector) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goPosition := engine.Vector{X: float64(position.X), Y: float64(position.Y)}
-	singleton.SetPosition(int(id), goPosition)
+	eng.SetPosition(int(id), goPosition)
 }
 
 //export SetAnchor
-func SetAnchor(id C.int32_t, anchor C.Vector) {
+func SetAnchor(handle C.EngineHandle, id C.int32_t, anchor C.Vector) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goAnchor := engine.Vector{X: float64(anchor.X), Y: float64(anchor.Y)}
-	singleton.SetAnchor(int(id), goAnchor)
+	eng.SetAnchor(int(id), goAnchor)
 }
 
 //export RemoveObject
-func RemoveObject(id C.int32_t) {
-	singleton.RemoveObject(int(id))
+func RemoveObject(handle C.EngineHandle, id C.int32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
+	eng.RemoveObject(int(id))
 }
 
 //export RemoveObjects
-func RemoveObjects(ids *C.int32_t, count C.int32_t) {
+func RemoveObjects(handle C.EngineHandle, ids *C.int32_t, count C.int32_t) {
+	eng := _getEngine(handle)
+	if eng == nil {
+		return
+	}
 	goIDs := make([]int, count)
 	idSlice := (*[1 << 30]C.int32_t)(unsafe.Pointer(ids))[:count:count]
 	for i, id := range idSlice {
 		goIDs[i] = int(id)
 	}
-	singleton.RemoveObjects(goIDs)
+	eng.RemoveObjects(goIDs)
+}
+
+//export AddPreTickHook
+func AddPreTickHook(handle C.EngineHandle, hook C.TickHook, userData unsafe.Pointer) C.uint32_t {
+	eng := _getEngine(handle)
+	if eng == nil || hook == nil {
+		return 0
+	}
+	return _addTickHook(handle, hook, userData, eng.AddPreTickHook)
+}
+
+//export AddPostTickHook
+func AddPostTickHook(handle C.EngineHandle, hook C.TickHook, userData unsafe.Pointer) C.uint32_t {
+	eng := _getEngine(handle)
+	if eng == nil || hook == nil {
+		return 0
+	}
+	return _addTickHook(handle, hook, userData, eng.AddPostTickHook)
+}
+
+//export RemoveTickHook
+func RemoveTickHook(id C.uint32_t) {
+	hooksMutex.Lock()
+	registered, ok := tickHooks[id]
+	delete(tickHooks, id)
+	hooksMutex.Unlock()
+	if ok {
+		registered.remove()
+	}
+}
+
+//export TickGetObjectPtr
+func TickGetObjectPtr(handle C.EngineHandle, id C.int32_t) *C.Object {
+	world := _getTickWorld(handle)
+	if world == nil {
+		return nil
+	}
+	return _convertObjectToC(world.Object(int(id)))
+}
+
+//export TickUpsertObject
+func TickUpsertObject(handle C.EngineHandle, obj *C.Object) {
+	world := _getTickWorld(handle)
+	if world == nil {
+		return
+	}
+	world.UpsertObject(_convertObjectToGo(obj))
+}
+
+//export TickRemoveObject
+func TickRemoveObject(handle C.EngineHandle, id C.int32_t) {
+	world := _getTickWorld(handle)
+	if world == nil {
+		return
+	}
+	world.RemoveObject(int(id))
+}
+
+//export TickAddImpulse
+func TickAddImpulse(handle C.EngineHandle, id C.int32_t, direction C.Vector, damping C.double) {
+	world := _getTickWorld(handle)
+	if world == nil {
+		return
+	}
+	world.AddImpulse(int(id), _convertVectorToGo(direction), float64(damping))
+}
+
+//export TickSetVelocity
+func TickSetVelocity(handle C.EngineHandle, id C.int32_t, velocity C.Vector) {
+	world := _getTickWorld(handle)
+	if world == nil {
+		return
+	}
+	world.SetVelocity(int(id), _convertVectorToGo(velocity))
+}
+
+//export TickSetPosition
+func TickSetPosition(handle C.EngineHandle, id C.int32_t, position C.Vector) {
+	world := _getTickWorld(handle)
+	if world == nil {
+		return
+	}
+	world.SetPosition(int(id), _convertVectorToGo(position))
 }
 
 //export FreeImpulsePtr
@@ -227,6 +889,138 @@ func FreeWorldPtr(cWorld *C.World) {
 	_freeWorld(cWorld)
 }
 
+// Get the engine instance by its handle, nil if the handle is unknown
+func _getEngine(handle C.EngineHandle) *engine.Engine {
+	enginesMutex.RLock()
+	defer enginesMutex.RUnlock()
+	return engines[handle]
+}
+
+// Register the C tick hook with the engine and return its id
+// While the hook runs its world is available to the Tick* functions by the engine handle
+func _addTickHook(handle C.EngineHandle, hook C.TickHook, userData unsafe.Pointer, add func(engine.TickHook) func()) C.uint32_t {
+	remove := add(func(world *engine.TickWorld, elapsed float64) {
+		hooksMutex.Lock()
+		previous := tickWorlds[handle]
+		tickWorlds[handle] = world
+		hooksMutex.Unlock()
+		defer func() {
+			hooksMutex.Lock()
+			tickWorlds[handle] = previous
+			if previous == nil {
+				delete(tickWorlds, handle)
+			}
+			hooksMutex.Unlock()
+		}()
+		C.callTickHook(hook, handle, C.uint64_t(world.Tick()), C.double(elapsed), userData)
+	})
+
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	lastHookID++
+	tickHooks[lastHookID] = tickHook{handle: handle, remove: remove}
+	return lastHookID
+}
+
+// Remove all the C tick hooks registered with the engine
+func _removeTickHooks(handle C.EngineHandle) {
+	hooksMutex.Lock()
+	var removed []func()
+	for id, registered := range tickHooks {
+		if registered.handle == handle {
+			removed = append(removed, registered.remove)
+			delete(tickHooks, id)
+		}
+	}
+	hooksMutex.Unlock()
+	for _, remove := range removed {
+		remove()
+	}
+}
+
+// Get the world of the tick hook running for the engine, nil outside of the hooks
+func _getTickWorld(handle C.EngineHandle) *engine.TickWorld {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	return tickWorlds[handle]
+}
+
+// Build the cast filter from the object type bitmask and the ignored object ID
+func _castFilter(typeMask C.uint32_t, ignoreID C.int32_t) engine.QueryFilter {
+	filter := _queryFilter(typeMask)
+	if ignoreID != -1 {
+		filter.Predicate = func(obj *engine.Object) bool { return obj.ID != int(ignoreID) }
+	}
+	return filter
+}
+
+// Convert the error of RunContext or Err to the C status
+func _runStatus(err error) C.RunStatus {
+	switch {
+	case err == nil:
+		return C.RunOK
+	case errors.Is(err, engine.ErrInvalidTick):
+		return C.RunInvalidTick
+	case errors.Is(err, engine.ErrNoWorld):
+		return C.RunNoWorld
+	case errors.Is(err, engine.ErrRunning):
+		return C.RunAlreadyRunning
+	default:
+		return C.RunFailed
+	}
+}
+
+// Build the query filter from the object type bitmask (1 << ObjectType, 0 for all types)
+func _queryFilter(typeMask C.uint32_t) engine.QueryFilter {
+	var filter engine.QueryFilter
+	for t := engine.Other; t <= engine.Item; t++ {
+		if typeMask&(1<<uint(t)) != 0 {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	return filter
+}
+
+// Write the object IDs to the C array, up to its capacity
+func _writeObjectIDs(objects []*engine.Object, ids *C.int32_t, capacity C.int32_t) C.int32_t {
+	if ids != nil && capacity > 0 {
+		cIDs := unsafe.Slice(ids, int(capacity))
+		for i, obj := range objects {
+			if i >= len(cIDs) {
+				break
+			}
+			cIDs[i] = C.int32_t(obj.ID)
+		}
+	}
+	return C.int32_t(len(objects))
+}
+
+// Write the cast result to the C hit, if any
+func _writeRaycastHit(result engine.RaycastHit, ok bool, hit *C.RaycastHit) C.uint8_t {
+	if !ok {
+		return 0
+	}
+	if hit != nil {
+		*hit = C.RaycastHit{
+			ObjectID: C.int32_t(result.ObjectID),
+			Point:    _convertVectorToC(result.Point),
+			Normal:   _convertVectorToC(result.Normal),
+			Distance: C.double(result.Distance),
+		}
+	}
+	return 1
+}
+
+// Helper function to convert a C vector to Go
+func _convertVectorToGo(vec C.Vector) engine.Vector {
+	return engine.Vector{X: float64(vec.X), Y: float64(vec.Y)}
+}
+
+// Helper function to convert a Go vector to C
+func _convertVectorToC(vec engine.Vector) C.Vector {
+	return C.Vector{X: C.double(vec.X), Y: C.double(vec.Y)}
+}
+
 // Helper function to convert bool to uint8
 func _boolToUint8(b bool) C.uint8_t {
 	if b {
@@ -309,6 +1103,10 @@ func _convertObjectToC(obj *engine.Object) *C.Object {
 
 	// Convert impulses
 	cObj.Impulses = _convertImpulsesToC(obj.Impulses)
+	cObj.Bullet = _boolToUint8(obj.Bullet)
+	cObj.Restitution = C.double(obj.Restitution)
+	cObj.Friction = C.double(obj.Friction)
+	cObj.Drag = C.double(obj.Drag)
 
 	return cObj
 }
@@ -328,6 +1126,10 @@ func _convertObjectToGo(cObj *C.Object) *engine.Object {
 		Position:      engine.Vector{X: float64(cObj.Position.X), Y: float64(cObj.Position.Y)},
 		Anchor:        engine.Vector{X: float64(cObj.Anchor.X), Y: float64(cObj.Anchor.Y)},
 		GravityFactor: float64(cObj.GravityFactor),
+		Bullet:        _uint8ToBool(cObj.Bullet),
+		Restitution:   float64(cObj.Restitution),
+		Friction:      float64(cObj.Friction),
+		Drag:          float64(cObj.Drag),
 	}
 
 	// Convert impulses
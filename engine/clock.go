@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"math"
+	"time"
+)
+
+// Default longest time advanced by a single update of the loop started by Run (seconds)
+// Long stalls (a debugger, a suspended app, a slow frame) are dropped instead of teleporting the objects
+const defaultMaxElapsed = 0.25
+
+// Freeze the world in the loop started by Run, e.g. while a menu is open
+// The loop keeps running, so the paused time is skipped and the world doesn't jump on Resume
+// Step, StepN and the other explicit calls still work while paused
+func (engine *Engine) Pause() {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.paused = true
+}
+
+// Continue updating the world paused by Pause from the current moment
+func (engine *Engine) Resume() {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	if !engine.paused {
+		return
+	}
+	engine.paused = false
+	engine.lastUpdate = time.Now()
+}
+
+// Check if the world is paused
+func (engine *Engine) IsPaused() bool {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return engine.paused
+}
+
+// Set the speed of the simulated time relative to the real time in the loop started by Run
+// 1 is the real time (default), 0.5 is the slow motion, 2 is twice as fast
+// Zero, negative and non-finite scales are ignored, use Pause to freeze the world
+func (engine *Engine) SetTimeScale(scale float64) {
+	if scale <= 0 || math.IsInf(scale, 0) || math.IsNaN(scale) {
+		return
+	}
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.timeScale = scale
+}
+
+// Get the speed of the simulated time relative to the real time
+func (engine *Engine) GetTimeScale() float64 {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return engine.getTimeScale()
+}
+
+// Set the longest simulated time advanced by a single update of the loop started by Run in milliseconds
+// The rest of the elapsed time is dropped, zero or negative maxMS restores the default of 250 ms
+func (engine *Engine) SetMaxElapsed(maxMS float64) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	if maxMS <= 0 || math.IsNaN(maxMS) {
+		engine.maxElapsed = 0
+	} else {
+		engine.maxElapsed = maxMS / 1000
+	}
+}
+
+// -- Internal methods -- //
+
+// Get the time scale, 1 if not set
+func (engine *Engine) getTimeScale() float64 {
+	if engine.timeScale <= 0 {
+		return 1
+	}
+	return engine.timeScale
+}
+
+// Scale the real elapsed time by the time scale and clamp it to the maximum elapsed time
+func (engine *Engine) scaleElapsed(elapsed float64) float64 {
+	maxElapsed := engine.maxElapsed
+	if maxElapsed <= 0 {
+		maxElapsed = defaultMaxElapsed
+	}
+	return min(elapsed*engine.getTimeScale(), maxElapsed)
+}
@@ -0,0 +1,66 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPauseFreezesElapsedTime(t *testing.T) {
+	eng := newCommandEngine()
+	eng.Pause()
+	if tick := eng.StepElapsed(0.05); tick != 0 || !eng.IsPaused() {
+		t.Fatalf("Expected no ticks while paused, got %d", tick)
+	}
+	if tick := eng.Step(0.01); tick != 1 {
+		t.Errorf("Expected Step to work while paused, got tick %d", tick)
+	}
+
+	eng.Resume()
+	if tick := eng.StepElapsed(0.02); tick != 2 || math.Abs(eng.GetTime()-0.03) > 1e-9 {
+		t.Errorf("Expected the elapsed time to advance the world after Resume, got tick %d at %f", tick, eng.GetTime())
+	}
+}
+
+func TestPauseFreezesRunLoop(t *testing.T) {
+	runWithTimeout(t, func(t *testing.T) {
+		eng := newCommandEngine()
+		eng.Pause()
+		eng.Run(1)
+		t.Cleanup(eng.Stop)
+
+		time.Sleep(20 * time.Millisecond)
+		if tick := eng.GetTick(); tick != 0 {
+			t.Fatalf("Expected no ticks while paused, got %d", tick)
+		}
+	})
+}
+
+func TestTimeScaleAndMaxElapsed(t *testing.T) {
+	eng := newCommandEngine()
+	eng.SetTimeScale(0.1)
+	eng.SetTimeScale(-1) // Ignored
+	if scale := eng.GetTimeScale(); scale != 0.1 {
+		t.Fatalf("Expected time scale 0.1, got %f", scale)
+	}
+	eng.StepElapsed(0.05)
+	if simulated := eng.GetTime(); math.Abs(simulated-0.005) > 1e-9 {
+		t.Errorf("Expected 0.005 seconds simulated in the slow motion, got %f", simulated)
+	}
+
+	// Updates never advance the world by more than the maximum elapsed time
+	eng = newCommandEngine()
+	eng.SetTimeScale(100)
+	eng.SetMaxElapsed(2)
+	eng.StepElapsed(0.01)
+	if simulated := eng.GetTime(); math.Abs(simulated-0.002) > 1e-9 {
+		t.Errorf("Expected at most 2 ms per update, got %f seconds", simulated)
+	}
+
+	// The default maximum is 250 ms
+	eng.SetMaxElapsed(0)
+	eng.StepElapsed(1)
+	if simulated := eng.GetTime(); math.Abs(simulated-0.252) > 1e-9 {
+		t.Errorf("Expected the default maximum of 250 ms, got %f seconds", simulated)
+	}
+}
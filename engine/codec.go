@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"fmt"
+
 	flatbuffers "github.com/google/flatbuffers/go"
 	"github.com/plugfox/slash-engine-go/generated/Game"
 )
@@ -17,10 +19,10 @@ func serializeImpulse(builder *flatbuffers.Builder, impulse *Impulse) flatbuffer
 	}
 
 	next := serializeImpulse(builder, impulse.Next) // Рекурсивная обработка
-	dir := serializeVector(builder, impulse.Direction)
 
+	// Структуры (Vector) должны создаваться внутри таблицы
 	Game.ImpulseStart(builder)
-	Game.ImpulseAddDirection(builder, dir)
+	Game.ImpulseAddDirection(builder, serializeVector(builder, impulse.Direction))
 	Game.ImpulseAddDamping(builder, impulse.Damping)
 	Game.ImpulseAddNext(builder, next)
 	return Game.ImpulseEnd(builder)
@@ -28,27 +30,28 @@ func serializeImpulse(builder *flatbuffers.Builder, impulse *Impulse) flatbuffer
 
 // Конвертация Object в FlatBuffers
 func serializeObject(builder *flatbuffers.Builder, obj *Object) flatbuffers.UOffsetT {
-	size := serializeVector(builder, obj.Size)
-	velocity := serializeVector(builder, obj.Velocity)
-	position := serializeVector(builder, obj.Position)
-	anchor := serializeVector(builder, obj.Anchor)
 	impulses := serializeImpulse(builder, obj.Impulses)
 
+	// Структуры (Vector) должны создаваться внутри таблицы
 	Game.ObjectStart(builder)
 	Game.ObjectAddID(builder, int32(obj.ID))
 	Game.ObjectAddType(builder, Game.ObjectType(obj.Type))
 	Game.ObjectAddClient(builder, obj.Client)
-	Game.ObjectAddSize(builder, size)
-	Game.ObjectAddVelocity(builder, velocity)
-	Game.ObjectAddPosition(builder, position)
-	Game.ObjectAddAnchor(builder, anchor)
+	Game.ObjectAddSize(builder, serializeVector(builder, obj.Size))
+	Game.ObjectAddVelocity(builder, serializeVector(builder, obj.Velocity))
+	Game.ObjectAddPosition(builder, serializeVector(builder, obj.Position))
+	Game.ObjectAddAnchor(builder, serializeVector(builder, obj.Anchor))
 	Game.ObjectAddGravityFactor(builder, obj.GravityFactor)
 	Game.ObjectAddImpulses(builder, impulses)
+	Game.ObjectAddBullet(builder, obj.Bullet)
+	Game.ObjectAddRestitution(builder, obj.Restitution)
+	Game.ObjectAddFriction(builder, obj.Friction)
+	Game.ObjectAddDrag(builder, obj.Drag)
 	return Game.ObjectEnd(builder)
 }
 
-// Конвертация World в FlatBuffers
-func serializeWorldToBytes(world *World) []byte {
+// Конвертация World в FlatBuffers вместе с заголовком
+func serializeWorldToBytes(world *World, header WorldHeader) []byte {
 	builder := flatbuffers.NewBuilder(1024)
 
 	// Преобразуем объекты
@@ -61,40 +64,82 @@ func serializeWorldToBytes(world *World) []byte {
 		builder.PrependUOffsetT(objects[i])
 	}
 	objectsVector := builder.EndVector(len(objects))
+	headerOffset := serializeWorldHeader(builder, header)
 
-	// Преобразуем гравитацию и границы
-	boundary := serializeVector(builder, world.Boundary)
-
-	// Создаём мир
+	// Создаём мир, границы (Vector) создаются внутри таблицы
 	Game.WorldStart(builder)
 	Game.WorldAddGravity(builder, world.Gravity)
-	Game.WorldAddBoundary(builder, boundary)
+	Game.WorldAddBoundary(builder, serializeVector(builder, world.Boundary))
 	Game.WorldAddObjects(builder, objectsVector)
+	Game.WorldAddHeader(builder, headerOffset)
 	worldOffset := Game.WorldEnd(builder)
 
 	builder.Finish(worldOffset)
 	return builder.FinishedBytes()
 }
 
+// Конвертация заголовка мира в FlatBuffers
+func serializeWorldHeader(builder *flatbuffers.Builder, header WorldHeader) flatbuffers.UOffsetT {
+	engineVersion := builder.CreateString(header.EngineVersion) // Строки создаются до таблицы
+
+	Game.WorldHeaderStart(builder)
+	Game.WorldHeaderAddFormatVersion(builder, header.FormatVersion)
+	Game.WorldHeaderAddMinReaderVersion(builder, header.MinReaderVersion)
+	Game.WorldHeaderAddEngineVersion(builder, engineVersion)
+	Game.WorldHeaderAddTick(builder, header.Tick)
+	Game.WorldHeaderAddTimestamp(builder, header.Timestamp)
+	return Game.WorldHeaderEnd(builder)
+}
+
+// Декодируем заголовок мира из проверенного буфера
+// В версии 1 формата заголовка нет
+func deserializeWorldHeader(data []byte) WorldHeader {
+	header := Game.GetRootAsWorld(data, 0).Header(nil)
+	if header == nil {
+		return WorldHeader{FormatVersion: 1, MinReaderVersion: 1}
+	}
+	return deserializeHeader(header)
+}
+
+// Декодируем заголовок дельты из проверенного буфера, верификатор требует его наличия
+func deserializeWorldDeltaHeader(data []byte) WorldHeader {
+	return deserializeHeader(Game.GetRootAsWorldDelta(data, 0).Header(nil))
+}
+
+// Декодируем заголовок из FlatBuffers
+func deserializeHeader(header *Game.WorldHeader) WorldHeader {
+	return WorldHeader{
+		FormatVersion:    header.FormatVersion(),
+		MinReaderVersion: header.MinReaderVersion(),
+		EngineVersion:    string(header.EngineVersion()),
+		Tick:             header.Tick(),
+		Timestamp:        header.Timestamp(),
+	}
+}
+
 // Декодируем Vector из FlatBuffers
 func deserializeVector(vec *Game.Vector) Vector {
+	if vec == nil {
+		return Vector{} // Поле не записано
+	}
 	return Vector{
 		X: vec.X(),
 		Y: vec.Y(),
 	}
 }
 
-// Декодируем Impulse из FlatBuffers
+// Декодируем цепочку Impulse из FlatBuffers
+// Цепочка читается в цикле, её длина ограничена верификатором (maxImpulseChain)
 func deserializeImpulse(impulse *Game.Impulse) *Impulse {
-	if impulse == nil {
-		return nil
-	}
-
-	return &Impulse{
-		Direction: deserializeVector(impulse.Direction(nil)),
-		Damping:   impulse.Damping(),
-		Next:      deserializeImpulse(impulse.Next(nil)),
+	var head *Impulse
+	for next := &head; impulse != nil; impulse = impulse.Next(nil) {
+		*next = &Impulse{
+			Direction: deserializeVector(impulse.Direction(nil)),
+			Damping:   impulse.Damping(),
+		}
+		next = &(*next).Next
 	}
+	return head
 }
 
 // Декодируем Object из FlatBuffers
@@ -109,6 +154,10 @@ func deserializeObject(obj *Game.Object) *Object {
 		Anchor:        deserializeVector(obj.Anchor(nil)),
 		GravityFactor: obj.GravityFactor(),
 		Impulses:      deserializeImpulse(obj.Impulses(nil)),
+		Bullet:        obj.Bullet(),
+		Restitution:   obj.Restitution(),
+		Friction:      obj.Friction(),
+		Drag:          obj.Drag(),
 	}
 }
 
@@ -136,3 +185,336 @@ func deserializeWorldFromBytes(data []byte) *World {
 		Objects:  objects,
 	}
 }
+
+// Конвертация изменений объекта в FlatBuffers, записываются только изменённые поля
+func serializeObjectDelta(builder *flatbuffers.Builder, obj *Object, fields deltaField) flatbuffers.UOffsetT {
+	var impulses flatbuffers.UOffsetT
+	if fields&deltaImpulses != 0 {
+		impulses = serializeImpulse(builder, obj.Impulses)
+	}
+
+	// Структуры (Vector) должны создаваться внутри таблицы
+	Game.ObjectDeltaStart(builder)
+	Game.ObjectDeltaAddID(builder, int32(obj.ID))
+	Game.ObjectDeltaAddFields(builder, uint32(fields))
+	if fields&deltaType != 0 {
+		Game.ObjectDeltaAddType(builder, Game.ObjectType(obj.Type))
+	}
+	if fields&deltaClient != 0 {
+		Game.ObjectDeltaAddClient(builder, obj.Client)
+	}
+	if fields&deltaSize != 0 {
+		Game.ObjectDeltaAddSize(builder, serializeVector(builder, obj.Size))
+	}
+	if fields&deltaVelocity != 0 {
+		Game.ObjectDeltaAddVelocity(builder, serializeVector(builder, obj.Velocity))
+	}
+	if fields&deltaPosition != 0 {
+		Game.ObjectDeltaAddPosition(builder, serializeVector(builder, obj.Position))
+	}
+	if fields&deltaAnchor != 0 {
+		Game.ObjectDeltaAddAnchor(builder, serializeVector(builder, obj.Anchor))
+	}
+	if fields&deltaGravityFactor != 0 {
+		Game.ObjectDeltaAddGravityFactor(builder, obj.GravityFactor)
+	}
+	if impulses != 0 {
+		Game.ObjectDeltaAddImpulses(builder, impulses)
+	}
+	if fields&deltaBullet != 0 {
+		Game.ObjectDeltaAddBullet(builder, obj.Bullet)
+	}
+	if fields&deltaRestitution != 0 {
+		Game.ObjectDeltaAddRestitution(builder, obj.Restitution)
+	}
+	if fields&deltaFriction != 0 {
+		Game.ObjectDeltaAddFriction(builder, obj.Friction)
+	}
+	if fields&deltaDrag != 0 {
+		Game.ObjectDeltaAddDrag(builder, obj.Drag)
+	}
+	return Game.ObjectDeltaEnd(builder)
+}
+
+// Конвертация разницы между базовым и текущим миром в FlatBuffers
+// Объекты обходятся по возрастанию ID, поэтому результат детерминирован
+func serializeWorldDelta(baseline *World, world *World) []byte {
+	if world == nil {
+		return nil
+	}
+	var baseObjects map[int]*Object
+	if baseline != nil {
+		baseObjects = baseline.Objects
+	}
+
+	builder := flatbuffers.NewBuilder(1024)
+
+	// Добавленные и изменённые объекты
+	var added, changed []flatbuffers.UOffsetT
+	for _, id := range sortedObjectIDs(world.Objects) {
+		obj := world.Objects[id]
+		base, ok := baseObjects[id]
+		if !ok {
+			added = append(added, serializeObject(builder, obj))
+		} else if fields := _diffObject(base, obj); fields != 0 {
+			changed = append(changed, serializeObjectDelta(builder, obj, fields))
+		}
+	}
+
+	// Удалённые объекты
+	var removed []int
+	for _, id := range sortedObjectIDs(baseObjects) {
+		if _, ok := world.Objects[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+
+	Game.WorldDeltaStartAddedVector(builder, len(added))
+	for i := len(added) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(added[i])
+	}
+	addedVector := builder.EndVector(len(added))
+
+	Game.WorldDeltaStartRemovedVector(builder, len(removed))
+	for i := len(removed) - 1; i >= 0; i-- {
+		builder.PrependInt32(int32(removed[i]))
+	}
+	removedVector := builder.EndVector(len(removed))
+
+	Game.WorldDeltaStartChangedVector(builder, len(changed))
+	for i := len(changed) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(changed[i])
+	}
+	changedVector := builder.EndVector(len(changed))
+	headerOffset := serializeWorldHeader(builder, _currentHeader(0, 0))
+
+	// Создаём дельту, границы (Vector) создаются внутри таблицы
+	Game.WorldDeltaStart(builder)
+	Game.WorldDeltaAddGravity(builder, world.Gravity)
+	Game.WorldDeltaAddBoundary(builder, serializeVector(builder, world.Boundary))
+	Game.WorldDeltaAddAdded(builder, addedVector)
+	Game.WorldDeltaAddRemoved(builder, removedVector)
+	Game.WorldDeltaAddChanged(builder, changedVector)
+	Game.WorldDeltaAddHeader(builder, headerOffset)
+	deltaOffset := Game.WorldDeltaEnd(builder)
+
+	builder.Finish(deltaOffset)
+	return builder.FinishedBytes()
+}
+
+// Применяем изменения из FlatBuffers к объекту
+func deserializeObjectDelta(obj *Object, delta *Game.ObjectDelta) {
+	fields := deltaField(delta.Fields())
+	if fields&deltaType != 0 {
+		obj.Type = ObjectType(delta.Type())
+	}
+	if fields&deltaClient != 0 {
+		obj.Client = delta.Client()
+	}
+	if fields&deltaSize != 0 {
+		obj.Size = deserializeVector(delta.Size(nil))
+	}
+	if fields&deltaVelocity != 0 {
+		obj.Velocity = deserializeVector(delta.Velocity(nil))
+	}
+	if fields&deltaPosition != 0 {
+		obj.Position = deserializeVector(delta.Position(nil))
+	}
+	if fields&deltaAnchor != 0 {
+		obj.Anchor = deserializeVector(delta.Anchor(nil))
+	}
+	if fields&deltaGravityFactor != 0 {
+		obj.GravityFactor = delta.GravityFactor()
+	}
+	if fields&deltaImpulses != 0 {
+		obj.Impulses = deserializeImpulse(delta.Impulses(nil))
+	}
+	if fields&deltaBullet != 0 {
+		obj.Bullet = delta.Bullet()
+	}
+	if fields&deltaRestitution != 0 {
+		obj.Restitution = delta.Restitution()
+	}
+	if fields&deltaFriction != 0 {
+		obj.Friction = delta.Friction()
+	}
+	if fields&deltaDrag != 0 {
+		obj.Drag = delta.Drag()
+	}
+}
+
+// Восстанавливаем мир из базового состояния и проверенной дельты из FlatBuffers
+func deserializeWorldDelta(baseline *World, data []byte) (*World, error) {
+	delta := Game.GetRootAsWorldDelta(data, 0)
+
+	// Копируем объекты базового мира, чтобы не изменять его
+	objects := make(map[int]*Object)
+	if baseline != nil {
+		for id, obj := range baseline.Objects {
+			objects[id] = obj.clone()
+		}
+	}
+
+	for i := 0; i < delta.RemovedLength(); i++ {
+		delete(objects, int(delta.Removed(i)))
+	}
+	for i := 0; i < delta.AddedLength(); i++ {
+		var obj Game.Object
+		if delta.Added(&obj, i) {
+			goObj := deserializeObject(&obj)
+			objects[goObj.ID] = goObj
+		}
+	}
+	for i := 0; i < delta.ChangedLength(); i++ {
+		var change Game.ObjectDelta
+		if delta.Changed(&change, i) {
+			id := int(change.ID())
+			obj, ok := objects[id]
+			if !ok {
+				// Дельта построена от другого базового мира
+				return nil, fmt.Errorf("%w: changed object %d is not in the baseline", ErrInvalidWorld, id)
+			}
+			deserializeObjectDelta(obj, &change)
+		}
+	}
+
+	return &World{
+		Gravity:  delta.Gravity(),
+		Boundary: deserializeVector(delta.Boundary(nil)),
+		Objects:  objects,
+	}, nil
+}
+
+// Конвертация пакета команд в FlatBuffers
+func serializeCommands(commands []Command) []byte {
+	builder := flatbuffers.NewBuilder(256)
+
+	offsets := make([]flatbuffers.UOffsetT, 0, len(commands))
+	for i := range commands {
+		offsets = append(offsets, serializeCommand(builder, &commands[i]))
+	}
+	Game.CommandBatchStartCommandsVector(builder, len(offsets))
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
+	}
+	vector := builder.EndVector(len(offsets))
+
+	Game.CommandBatchStart(builder)
+	Game.CommandBatchAddCommands(builder, vector)
+	builder.Finish(Game.CommandBatchEnd(builder))
+	return builder.FinishedBytes()
+}
+
+// Конвертация команды клиента в FlatBuffers
+func serializeCommand(builder *flatbuffers.Builder, command *Command) flatbuffers.UOffsetT {
+	var kind Game.Command
+	var body flatbuffers.UOffsetT
+	switch command.Kind {
+	case CommandMove:
+		Game.MoveCommandStart(builder)
+		Game.MoveCommandAddObjectID(builder, int32(command.ObjectID))
+		Game.MoveCommandAddVelocity(builder, serializeVector(builder, command.Vector))
+		kind, body = Game.CommandMoveCommand, Game.MoveCommandEnd(builder)
+	case CommandJump:
+		Game.JumpCommandStart(builder)
+		Game.JumpCommandAddObjectID(builder, int32(command.ObjectID))
+		Game.JumpCommandAddSpeed(builder, command.Speed)
+		kind, body = Game.CommandJumpCommand, Game.JumpCommandEnd(builder)
+	case CommandImpulse:
+		Game.ImpulseCommandStart(builder)
+		Game.ImpulseCommandAddObjectID(builder, int32(command.ObjectID))
+		Game.ImpulseCommandAddDirection(builder, serializeVector(builder, command.Vector))
+		Game.ImpulseCommandAddDamping(builder, command.Damping)
+		kind, body = Game.CommandImpulseCommand, Game.ImpulseCommandEnd(builder)
+	case CommandSpawn:
+		var obj flatbuffers.UOffsetT
+		if command.Object != nil {
+			obj = serializeObject(builder, command.Object)
+		}
+		Game.SpawnCommandStart(builder)
+		Game.SpawnCommandAddObject(builder, obj)
+		kind, body = Game.CommandSpawnCommand, Game.SpawnCommandEnd(builder)
+	case CommandRemove:
+		Game.RemoveCommandStart(builder)
+		Game.RemoveCommandAddObjectID(builder, int32(command.ObjectID))
+		kind, body = Game.CommandRemoveCommand, Game.RemoveCommandEnd(builder)
+	}
+
+	Game.ClientCommandStart(builder)
+	Game.ClientCommandAddSequence(builder, command.Sequence)
+	Game.ClientCommandAddTick(builder, command.Tick)
+	if kind != Game.CommandNONE {
+		Game.ClientCommandAddCommandType(builder, kind)
+		Game.ClientCommandAddCommand(builder, body)
+	}
+	return Game.ClientCommandEnd(builder)
+}
+
+// Декодируем пакет команд из FlatBuffers
+// Буфер сначала проверяется верификатором, некорректный буфер возвращает ошибку вместо паники
+func deserializeCommands(data []byte) ([]Command, error) {
+	if err := _verifyCommandBatch(data); err != nil {
+		return nil, err
+	}
+
+	batch := Game.GetRootAsCommandBatch(data, 0)
+	commands := make([]Command, 0, batch.CommandsLength())
+	var client Game.ClientCommand
+	for i := 0; i < batch.CommandsLength(); i++ {
+		batch.Commands(&client, i)
+		command, err := deserializeCommand(&client)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// Декодируем команду клиента из FlatBuffers
+func deserializeCommand(client *Game.ClientCommand) (Command, error) {
+	command := Command{Sequence: client.Sequence(), Tick: client.Tick()}
+	var table flatbuffers.Table
+	if !client.Command(&table) {
+		return Command{}, fmt.Errorf("%w: command %d has no body", ErrInvalidCommand, command.Sequence)
+	}
+	switch client.CommandType() {
+	case Game.CommandMoveCommand:
+		var move Game.MoveCommand
+		move.Init(table.Bytes, table.Pos)
+		command.Kind = CommandMove
+		command.ObjectID = int(move.ObjectID())
+		command.Vector = deserializeVector(move.Velocity(nil))
+	case Game.CommandJumpCommand:
+		var jump Game.JumpCommand
+		jump.Init(table.Bytes, table.Pos)
+		command.Kind = CommandJump
+		command.ObjectID = int(jump.ObjectID())
+		command.Speed = jump.Speed()
+	case Game.CommandImpulseCommand:
+		var impulse Game.ImpulseCommand
+		impulse.Init(table.Bytes, table.Pos)
+		command.Kind = CommandImpulse
+		command.ObjectID = int(impulse.ObjectID())
+		command.Vector = deserializeVector(impulse.Direction(nil))
+		command.Damping = impulse.Damping()
+	case Game.CommandSpawnCommand:
+		var spawn Game.SpawnCommand
+		spawn.Init(table.Bytes, table.Pos)
+		obj := spawn.Object(nil)
+		if obj == nil {
+			return Command{}, fmt.Errorf("%w: spawn command %d has no object", ErrInvalidCommand, command.Sequence)
+		}
+		command.Kind = CommandSpawn
+		command.Object = deserializeObject(obj)
+		command.ObjectID = command.Object.ID
+	case Game.CommandRemoveCommand:
+		var remove Game.RemoveCommand
+		remove.Init(table.Bytes, table.Pos)
+		command.Kind = CommandRemove
+		command.ObjectID = int(remove.ObjectID())
+	default:
+		return Command{}, fmt.Errorf("%w: unknown command type %d", ErrInvalidCommand, client.CommandType())
+	}
+	return command, nil
+}
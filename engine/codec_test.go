@@ -0,0 +1,36 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestWorldBytesRoundTrip(t *testing.T) {
+	world := newTestWorld(&engine.Object{
+		ID:            7,
+		Type:          engine.Projectile,
+		Size:          engine.Vector{X: 10, Y: 2},
+		Position:      engine.Vector{X: 100, Y: 200},
+		Velocity:      engine.Vector{X: 3000},
+		GravityFactor: 1,
+		Impulses:      &engine.Impulse{Direction: engine.Vector{Y: 50}, Damping: 0.5},
+		Bullet:        true,
+		Restitution:   0.5,
+		Friction:      2,
+		Drag:          0.1,
+	})
+
+	decoded, err := engine.WorldFromBytes(world.ToBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := decoded.Objects[7]
+	if obj == nil {
+		t.Fatal("Expected object 7 to be decoded")
+	}
+	if !obj.Bullet || obj.Velocity.X != 3000 || obj.Impulses == nil || obj.Impulses.Damping != 0.5 ||
+		obj.Restitution != 0.5 || obj.Friction != 2 || obj.Drag != 0.1 {
+		t.Errorf("Expected object to survive the round trip, got %+v", obj)
+	}
+}
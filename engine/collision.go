@@ -0,0 +1,271 @@
+package engine
+
+import "math"
+
+// Contact between two overlapping objects
+type contact struct {
+	a, b   *Object // Colliding objects, normal points from b to a
+	normal Vector  // Contact normal (unit vector along the X or Y axis)
+	depth  float64 // Penetration depth along the normal
+}
+
+// Check if two object types can collide with each other
+//
+// - Terrain and Structure block Creature, Item and Projectile
+// - Creature blocks Creature and Projectile
+// - Effect and Other pass through everything
+// - Items don't block creatures, so they can be picked up
+func _collides(a, b ObjectType) bool {
+	if a > b {
+		a, b = b, a // Normalize the order, the rules are symmetric
+	}
+	switch a {
+	case Creature:
+		return b == Creature || b == Projectile || b == Terrain || b == Structure
+	case Projectile:
+		return b == Terrain || b == Structure
+	case Terrain, Structure:
+		return b == Item
+	case Other, Effect, Item:
+		return false
+	}
+	return false
+}
+
+// Collision rank of the object type
+// The object with the lower rank yields to the object with the higher rank,
+// objects with equal rank share the response
+func _collisionRank(t ObjectType) int {
+	switch t {
+	case Terrain, Structure:
+		return 2 // Static, never moved by collisions
+	case Creature:
+		return 1
+	case Other, Projectile, Effect, Item:
+		return 0
+	}
+	return 0
+}
+
+// Share of the collision response taken by each object
+func _collisionWeights(a, b *Object) (float64, float64) {
+	rankA, rankB := _collisionRank(a.Type), _collisionRank(b.Type)
+	switch {
+	case rankA < rankB:
+		return 1, 0
+	case rankA > rankB:
+		return 0, 1
+	default:
+		return 0.5, 0.5
+	}
+}
+
+// Check if two objects overlap and calculate the contact
+// The contact normal is the axis the objects entered the overlap most recently
+// (penetration depth divided by the relative speed along the axis),
+// so thin fast objects are not pushed out sideways
+func _intersect(a, b *Object) (contact, bool) {
+	overlapX := math.Min(a.positionRightX(), b.positionRightX()) - math.Max(a.positionLeftX(), b.positionLeftX())
+	if overlapX <= 0 {
+		return contact{}, false
+	}
+	overlapY := math.Min(a.positionTopY(), b.positionTopY()) - math.Max(a.positionBottomY(), b.positionBottomY())
+	if overlapY <= 0 {
+		return contact{}, false
+	}
+
+	relative := Vector{X: a.Velocity.X - b.Velocity.X, Y: a.Velocity.Y - b.Velocity.Y}
+	if _entryTime(overlapX, relative.X) < _entryTime(overlapY, relative.Y) {
+		normal := Vector{X: 1}
+		if a.Position.X < b.Position.X {
+			normal.X = -1
+		}
+		return contact{a: a, b: b, normal: normal, depth: overlapX}, true
+	}
+	normal := Vector{Y: 1}
+	if a.Position.Y < b.Position.Y {
+		normal.Y = -1
+	}
+	return contact{a: a, b: b, normal: normal, depth: overlapY}, true
+}
+
+// Time passed since the objects started to overlap along the axis
+// Axes without relative movement fall back to the penetration depth
+func _entryTime(overlap, speed float64) float64 {
+	if math.Abs(speed) < negligibleFloat {
+		return overlap * 1e6 // No relative movement, the least likely axis
+	}
+	return overlap / math.Abs(speed)
+}
+
+// Resolve the contact: separate the objects
+// and remove the relative velocity along the contact normal
+// Restitution 0 stops the objects, 1 reflects the velocity completely
+func _resolveContact(c contact, restitution float64) {
+	weightA, weightB := _collisionWeights(c.a, c.b)
+
+	// Separate overlapping objects along the contact normal
+	c.a.Position.X += c.normal.X * c.depth * weightA
+	c.a.Position.Y += c.normal.Y * c.depth * weightA
+	c.b.Position.X -= c.normal.X * c.depth * weightB
+	c.b.Position.Y -= c.normal.Y * c.depth * weightB
+
+	// Skip if the objects are already moving apart
+	relative := Vector{X: c.a.Velocity.X - c.b.Velocity.X, Y: c.a.Velocity.Y - c.b.Velocity.Y}
+	velocityAlongNormal := relative.dot(c.normal)
+	if velocityAlongNormal >= 0 {
+		return
+	}
+
+	// Zero or reflect the velocity along the contact normal
+	j := -(1 + restitution) * velocityAlongNormal
+	c.a.Velocity.X += c.normal.X * j * weightA
+	c.a.Velocity.Y += c.normal.Y * j * weightA
+	c.b.Velocity.X -= c.normal.X * j * weightB
+	c.b.Velocity.Y -= c.normal.Y * j * weightB
+
+	// Impulse damping during collisions,
+	// impulses can't keep pushing the object into the obstacle
+	if weightA > 0 {
+		_dampImpulsesAlong(c.a, c.normal)
+	}
+	if weightB > 0 {
+		_dampImpulsesAlong(c.b, Vector{X: -c.normal.X, Y: -c.normal.Y})
+	}
+}
+
+// Remove the impulse components pushing the object against the normal
+func _dampImpulsesAlong(obj *Object, normal Vector) {
+	for imp := obj.Impulses; imp != nil; imp = imp.Next {
+		if push := imp.Direction.dot(normal); push < 0 {
+			imp.Direction.X -= normal.X * push
+			imp.Direction.Y -= normal.Y * push
+		}
+	}
+}
+
+// Detect and resolve collisions between all objects of the world
+// Candidate pairs come from the spatial index, static colliders are
+// checked from the side of the moving object they collide with
+// Objects are processed in ID order to keep the simulation deterministic
+func _resolveCollisions(world *World) {
+	index := world.spatialIndex()
+	var candidates, hits []*Object
+	for _, id := range sortedObjectIDs(world.Objects) {
+		a := world.Objects[id]
+		if _isStatic(a.Type) || a.Type == Effect || a.Type == Other {
+			continue // Statics are checked from the other side, effects pass through
+		}
+
+		// Narrow down the candidates to the overlapping ones
+		candidates = index.queryObject(a, candidates)
+		hits = hits[:0]
+		for _, b := range candidates {
+			if b == a || !_isStatic(b.Type) && b.ID < a.ID {
+				continue // Pairs of moving objects are handled once, by the lower ID
+			}
+			if _collides(a.Type, b.Type) && _overlaps(a, b) {
+				hits = append(hits, b)
+			}
+		}
+		sortObjectsByID(hits)
+
+		for _, b := range hits {
+			if c, ok := _intersect(a, b); ok {
+				_resolveContact(c, _combineMaterial(a.Restitution, b.Restitution))
+				index.upsert(a)
+				index.upsert(b)
+			}
+		}
+	}
+}
+
+// Check if the object type is a static solid collider (terrain or structure)
+func _isStatic(t ObjectType) bool {
+	return t == Terrain || t == Structure
+}
+
+// Keep the static object in place, static objects ignore velocity and impulses
+func _makeStatic(obj *Object) {
+	obj.Velocity = Vector{}
+	obj.Impulses = nil
+}
+
+// Move the object by the displacement one axis at a time,
+// stopping at the sides, tops and undersides of the static colliders
+// Resolving the axes separately lets objects land on platforms
+// and walk along them without snagging on the corners of adjacent tiles
+func _moveWithStatics(obj *Object, world *World, displacement Vector) {
+	var candidates []*Object
+
+	// Horizontal movement, blocked by the sides of the statics
+	obj.Position.X += displacement.X
+	if displacement.X != 0 {
+		var hit *Object
+		edge := 0.0
+		for _, other := range _staticCandidates(obj, world, &candidates) {
+			if other == obj || !_isStatic(other.Type) || !_collides(obj.Type, other.Type) || !_overlaps(obj, other) {
+				continue
+			}
+			if displacement.X > 0 && (hit == nil || other.positionLeftX() < edge) {
+				edge, hit = other.positionLeftX(), other
+			} else if displacement.X < 0 && (hit == nil || other.positionRightX() > edge) {
+				edge, hit = other.positionRightX(), other
+			}
+		}
+		if hit != nil {
+			normal := Vector{X: -1} // Bumped into the left side of the static
+			obj.Position.X = edge - obj.Size.X/2
+			if displacement.X < 0 {
+				normal.X = 1 // Bumped into the right side of the static
+				obj.Position.X = edge + obj.Size.X/2
+			}
+			if obj.Velocity.X*normal.X < 0 {
+				obj.Velocity.X = _bounce(obj.Velocity.X, _combineMaterial(obj.Restitution, hit.Restitution))
+			}
+			_dampImpulsesAlong(obj, normal)
+		}
+	}
+
+	// Vertical movement, land on the tops and bump into the undersides
+	obj.Position.Y += displacement.Y
+	if displacement.Y != 0 {
+		var hit *Object
+		edge := 0.0
+		for _, other := range _staticCandidates(obj, world, &candidates) {
+			if other == obj || !_isStatic(other.Type) || !_collides(obj.Type, other.Type) || !_overlaps(obj, other) {
+				continue
+			}
+			if displacement.Y < 0 && (hit == nil || other.positionTopY() > edge) {
+				edge, hit = other.positionTopY(), other
+			} else if displacement.Y > 0 && (hit == nil || other.positionBottomY() < edge) {
+				edge, hit = other.positionBottomY(), other
+			}
+		}
+		if hit != nil {
+			normal := Vector{Y: 1} // Landed on the top of the static
+			obj.Position.Y = edge + obj.Size.Y/2
+			if displacement.Y > 0 {
+				normal.Y = -1 // Bumped the head into the underside of the static
+				obj.Position.Y = edge - obj.Size.Y/2
+			}
+			if obj.Velocity.Y*normal.Y < 0 {
+				obj.Velocity.Y = _bounce(obj.Velocity.Y, _combineMaterial(obj.Restitution, hit.Restitution))
+			}
+			_dampImpulsesAlong(obj, normal)
+		}
+	}
+}
+
+// Check if the boxes of two objects overlap (touching edges don't overlap)
+func _overlaps(a, b *Object) bool {
+	return a.positionLeftX() < b.positionRightX() && a.positionRightX() > b.positionLeftX() &&
+		a.positionBottomY() < b.positionTopY() && a.positionTopY() > b.positionBottomY()
+}
+
+// Objects of the world that can overlap the object, according to the spatial index
+// Statics never move, so the index is always up to date for them
+func _staticCandidates(obj *Object, world *World, buf *[]*Object) []*Object {
+	*buf = world.spatialIndex().queryObject(obj, *buf)
+	return *buf
+}
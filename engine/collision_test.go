@@ -0,0 +1,106 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// newTestWorld creates a world without gravity with the given objects.
+func newTestWorld(objects ...*engine.Object) *engine.World {
+	world := &engine.World{
+		Gravity:  0,
+		Boundary: engine.Vector{X: 1000, Y: 1000},
+		Objects:  make(map[int]*engine.Object, len(objects)),
+	}
+	for _, obj := range objects {
+		world.Objects[obj.ID] = obj
+	}
+	return world
+}
+
+func TestCreaturesSeparate(t *testing.T) {
+	a := &engine.Object{ID: 1, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 20}, Position: engine.Vector{X: 100, Y: 500}, Velocity: engine.Vector{X: 10}}
+	b := &engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 20}, Position: engine.Vector{X: 115, Y: 500}, Velocity: engine.Vector{X: -10}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(a, b), 0)
+	eng.Step(0.01)
+
+	if gap := b.Position.X - a.Position.X; gap < 20-1e-9 {
+		t.Errorf("Expected creatures to be separated, got gap %f", gap)
+	}
+	if a.Velocity.X != 0 || b.Velocity.X != 0 {
+		t.Errorf("Expected velocity along the normal to be zeroed, got %f and %f", a.Velocity.X, b.Velocity.X)
+	}
+}
+
+func TestProjectileStopsAtStructure(t *testing.T) {
+	wall := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 20, Y: 200}, Position: engine.Vector{X: 200, Y: 500}}
+	arrow := &engine.Object{ID: 2, Type: engine.Projectile, Size: engine.Vector{X: 10, Y: 2}, Position: engine.Vector{X: 180, Y: 500}, Velocity: engine.Vector{X: 100}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(wall, arrow), 0)
+	eng.Step(0.1)
+
+	if wall.Position.X != 200 {
+		t.Errorf("Expected structure to stay in place, got %f", wall.Position.X)
+	}
+	if arrow.Position.X > 185+1e-9 {
+		t.Errorf("Expected projectile to be pushed out of the structure, got %f", arrow.Position.X)
+	}
+	if arrow.Velocity.X != 0 {
+		t.Errorf("Expected projectile to stop, got velocity %f", arrow.Velocity.X)
+	}
+}
+
+func TestEffectPassesThroughTerrain(t *testing.T) {
+	ground := &engine.Object{ID: 1, Type: engine.Terrain, Size: engine.Vector{X: 200, Y: 20}, Position: engine.Vector{X: 500, Y: 500}}
+	smoke := &engine.Object{ID: 2, Type: engine.Effect, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 500}, Velocity: engine.Vector{Y: -10}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(ground, smoke), 0)
+	eng.Step(0.1)
+
+	if smoke.Position.Y != 499 || smoke.Velocity.Y != -10 {
+		t.Errorf("Expected effect to pass through terrain, got position %f and velocity %f", smoke.Position.Y, smoke.Velocity.Y)
+	}
+}
+
+func TestCreatureLandsOnPlatform(t *testing.T) {
+	platform := &engine.Object{ID: 1, Type: engine.Terrain, Size: engine.Vector{X: 200, Y: 20}, Position: engine.Vector{X: 500, Y: 300}}
+	hero := &engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 500, Y: 400}, GravityFactor: 1}
+
+	world := newTestWorld(platform, hero)
+	world.Gravity = 10
+	eng := &engine.Engine{}
+	eng.SetWorld(world, 0)
+	eng.StepN(100, 0.05)
+
+	if hero.Position.Y != 330 {
+		t.Errorf("Expected creature to stand on the platform at 330, got %f", hero.Position.Y)
+	}
+	if platform.Position.Y != 300 {
+		t.Errorf("Expected platform to stay in place, got %f", platform.Position.Y)
+	}
+}
+
+func TestCreatureBlockedByWallAndCeiling(t *testing.T) {
+	wall := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 20, Y: 200}, Position: engine.Vector{X: 200, Y: 100}}
+	ceiling := &engine.Object{ID: 2, Type: engine.Terrain, Size: engine.Vector{X: 100, Y: 20}, Position: engine.Vector{X: 100, Y: 200}}
+	hero := &engine.Object{ID: 3, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 100, Y: 150}, Velocity: engine.Vector{X: 400, Y: 400}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(wall, ceiling, hero), 0)
+	eng.StepN(10, 0.05)
+
+	if hero.Position.X != 180 {
+		t.Errorf("Expected creature to be blocked by the wall at 180, got %f", hero.Position.X)
+	}
+	if hero.Position.Y != 170 {
+		t.Errorf("Expected creature to bump into the ceiling at 170, got %f", hero.Position.Y)
+	}
+	if hero.Velocity.X != 0 || hero.Velocity.Y != 0 {
+		t.Errorf("Expected creature to stop, got velocity %v", hero.Velocity)
+	}
+}
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidCommand is returned when a command batch can't be decoded or applied
+var ErrInvalidCommand = errors.New("engine: invalid command")
+
+// ErrUnauthorizedCommand is returned when the client isn't allowed to apply a command of the batch
+var ErrUnauthorizedCommand = errors.New("engine: unauthorized command")
+
+type CommandKind int
+
+const (
+	// CommandMove sets the velocity of the object (Vector is the velocity)
+	CommandMove CommandKind = iota
+
+	// CommandJump sets the vertical speed of the object standing on the floor or on another object
+	CommandJump
+
+	// CommandImpulse adds an impulse to the object (Vector is the direction)
+	CommandImpulse
+
+	// CommandSpawn adds the Object to the world, its ID must not be taken
+	CommandSpawn
+
+	// CommandRemove removes the object from the world
+	CommandRemove
+)
+
+// Command is a remote client request to change the world
+type Command struct {
+	Sequence uint32      // Sequence number of the command, grows with each command of the client
+	Tick     uint64      // Client tick the command was issued at
+	Kind     CommandKind // Kind of the command
+	ObjectID int         // Target object, the spawned object ID for spawn commands
+	Vector   Vector      // Move velocity or impulse direction
+	Speed    float64     // Jump speed
+	Damping  float64     // Impulse damping
+	Object   *Object     // Spawned object
+}
+
+// CommandAuthorizer decides if the client may apply the command, e.g. owns the target object
+// The target is the object the command changes, for spawn commands it's the spawned command.Object,
+// a target spawned earlier in the same batch is its command.Object too
+// Called under the engine lock for every command of the batch: the command and the target
+// are read-only and must not be kept, and the engine methods can't be called from it
+type CommandAuthorizer func(clientID uint32, command *Command, target *Object) bool
+
+// AllowAllCommands lets every client apply any command,
+// for the trusted callers applying their own commands or the commands they already checked
+func AllowAllCommands(clientID uint32, command *Command, target *Object) bool {
+	return true
+}
+
+// Serialize the commands into a FlatBuffers CommandBatch for ApplyCommands
+func CommandsToBytes(commands []Command) []byte {
+	return serializeCommands(commands)
+}
+
+// Deserialize the commands from a FlatBuffers CommandBatch
+// Returns ErrInvalidCommand for malformed data
+func CommandsFromBytes(data []byte) ([]Command, error) {
+	return deserializeCommands(data)
+}
+
+// Decode a batch of commands of the client (see CommandsToBytes) and apply them in order
+// The batch is atomic: it's validated as a whole first, and malformed data, non-finite values,
+// unknown objects or object types, taken spawn IDs or not growing sequence numbers reject the whole batch
+// with ErrInvalidCommand, a command the authorizer doesn't allow rejects it with ErrUnauthorizedCommand,
+// otherwise all commands are applied under a single lock
+// A nil authorizer rejects every batch, so the clients can't change the world unless allowed,
+// trusted callers pass AllowAllCommands
+// The sequence numbers must also grow between the batches of the client,
+// so stale and replayed batches are rejected, see ResetCommands
+// Returns the sequence number of the last command to acknowledge
+//
+// The server package doesn't use the commands: its clients send the predicted inputs (see Input)
+// that Reconcile replays, the commands are for the hosts with their own transport (see README.md)
+func (engine *Engine) ApplyCommands(clientID uint32, data []byte, authorize CommandAuthorizer) (uint32, error) {
+	commands, err := deserializeCommands(data)
+	if err != nil {
+		return 0, err
+	}
+
+	defer engine.dispatchEvents()
+	engine.lock()
+	defer engine.mutex.Unlock()
+	world := engine.getWorld()
+	if world == nil {
+		return 0, fmt.Errorf("%w: no world", ErrInvalidCommand)
+	}
+	if applied, ok := engine.commandSequences[clientID]; ok && len(commands) > 0 && commands[0].Sequence <= applied {
+		return 0, fmt.Errorf("%w: sequence %d after the applied %d", ErrInvalidCommand, commands[0].Sequence, applied)
+	}
+	if err := _validateCommands(world, clientID, commands, authorize); err != nil {
+		return 0, err
+	}
+	var last uint32
+	for i := range commands {
+		engine.applyCommand(world, &commands[i])
+		last = commands[i].Sequence
+	}
+	if len(commands) > 0 {
+		if engine.commandSequences == nil {
+			engine.commandSequences = make(map[uint32]uint32)
+		}
+		engine.commandSequences[clientID] = last
+	}
+	return last, nil
+}
+
+// Forget the sequence of the last command applied for the client,
+// e.g. when it disconnects, so its next batch may start over from any sequence
+func (engine *Engine) ResetCommands(clientID uint32) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	delete(engine.commandSequences, clientID)
+}
+
+// -- Internal methods -- //
+
+// Apply the validated command to the world
+func (engine *Engine) applyCommand(world *World, command *Command) {
+	switch command.Kind {
+	case CommandMove:
+		world.Objects[command.ObjectID].Velocity = command.Vector
+	case CommandJump:
+		obj := world.Objects[command.ObjectID]
+		if !obj.movingUpward() && _isSupported(world, obj) {
+			obj.Velocity.Y = command.Speed
+		}
+	case CommandImpulse:
+		obj := world.Objects[command.ObjectID]
+		obj.Impulses = &Impulse{Direction: command.Vector, Damping: command.Damping, Next: obj.Impulses}
+	case CommandSpawn:
+		obj := command.Object
+		world.Objects[obj.ID] = obj
+		world.indexUpsert(obj)
+		engine.emitAdded(obj)
+	case CommandRemove:
+		engine.emitRemoved(world.Objects[command.ObjectID])
+		delete(world.Objects, command.ObjectID)
+		world.indexRemove(command.ObjectID)
+	}
+}
+
+// Check the whole batch against the world and the authorizer before applying any command
+func _validateCommands(world *World, clientID uint32, commands []Command, authorize CommandAuthorizer) error {
+	changed := make(map[int]*Object) // Objects spawned (the object) or removed (nil) by the batch
+	lookup := func(id int) *Object {
+		if obj, ok := changed[id]; ok {
+			return obj
+		}
+		return world.Objects[id]
+	}
+	allowed := func(command *Command, target *Object) error {
+		if authorize == nil || !authorize(clientID, command, target) {
+			return fmt.Errorf("%w: client %d command %d on object %d", ErrUnauthorizedCommand, clientID, command.Sequence, target.ID)
+		}
+		return nil
+	}
+
+	for i := range commands {
+		command := &commands[i]
+		if i > 0 && command.Sequence <= commands[i-1].Sequence {
+			return fmt.Errorf("%w: sequence %d after %d", ErrInvalidCommand, command.Sequence, commands[i-1].Sequence)
+		}
+		if command.Kind == CommandSpawn {
+			obj := command.Object
+			if obj == nil || obj.ID != command.ObjectID {
+				return fmt.Errorf("%w: spawn command %d has no object", ErrInvalidCommand, command.Sequence)
+			}
+			if obj.Type < Other || obj.Type > Item {
+				return fmt.Errorf("%w: object %d has unknown type %d", ErrInvalidCommand, obj.ID, obj.Type)
+			}
+			if lookup(obj.ID) != nil {
+				return fmt.Errorf("%w: object %d already exists", ErrInvalidCommand, obj.ID)
+			}
+			if !_isFiniteVector(obj.Position) || !_isFiniteVector(obj.Velocity) || !_isFiniteVector(obj.Anchor) ||
+				!_isFiniteVector(obj.Size) || obj.Size.X < 0 || obj.Size.Y < 0 {
+				return fmt.Errorf("%w: object %d has invalid geometry", ErrInvalidCommand, obj.ID)
+			}
+			if err := allowed(command, obj); err != nil {
+				return err
+			}
+			changed[obj.ID] = obj
+			continue
+		}
+
+		target := lookup(command.ObjectID)
+		if target == nil {
+			return fmt.Errorf("%w: object %d not found", ErrInvalidCommand, command.ObjectID)
+		}
+		switch command.Kind {
+		case CommandMove, CommandImpulse:
+			if !_isFiniteVector(command.Vector) || math.IsNaN(command.Damping) || math.IsInf(command.Damping, 0) {
+				return fmt.Errorf("%w: non-finite command %d", ErrInvalidCommand, command.Sequence)
+			}
+		case CommandJump:
+			if math.IsNaN(command.Speed) || math.IsInf(command.Speed, 0) {
+				return fmt.Errorf("%w: non-finite command %d", ErrInvalidCommand, command.Sequence)
+			}
+		case CommandRemove:
+			changed[command.ObjectID] = nil
+		default:
+			return fmt.Errorf("%w: unknown command kind %d", ErrInvalidCommand, command.Kind)
+		}
+		if err := allowed(command, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check if the object stands on the floor or on top of another object
+func _isSupported(world *World, obj *Object) bool {
+	if obj.onTheFloor() {
+		return true
+	}
+	candidates := world.spatialIndex().query(
+		obj.positionLeftX(), obj.positionBottomY()-contactTolerance,
+		obj.positionRightX(), obj.positionBottomY()+contactTolerance,
+		nil,
+	)
+	for _, other := range candidates {
+		if other == obj || !_collides(obj.Type, other.Type) {
+			continue
+		}
+		if normal, ok := _touching(obj, other); ok && normal.Y > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if both components of the vector are finite numbers
+func _isFiniteVector(vec Vector) bool {
+	return !math.IsNaN(vec.X) && !math.IsInf(vec.X, 0) && !math.IsNaN(vec.Y) && !math.IsInf(vec.Y, 0)
+}
@@ -0,0 +1,236 @@
+package engine_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/plugfox/slash-engine-go/engine"
+	"github.com/plugfox/slash-engine-go/generated/Game"
+)
+
+// newCommandEngine creates an engine with a creature on the floor and another one in the air.
+func newCommandEngine() *engine.Engine {
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(
+		&engine.Object{ID: 1, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 100, Y: 20}},
+		&engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 300, Y: 200}},
+	), 0)
+	return eng
+}
+
+func TestCommandsBytesRoundTrip(t *testing.T) {
+	commands := []engine.Command{
+		{Sequence: 1, Tick: 10, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 5, Y: -3}},
+		{Sequence: 2, Tick: 10, Kind: engine.CommandJump, ObjectID: 1, Speed: 250},
+		{Sequence: 3, Tick: 11, Kind: engine.CommandImpulse, ObjectID: 2, Vector: engine.Vector{X: 1}, Damping: 0.5},
+		{Sequence: 4, Tick: 12, Kind: engine.CommandSpawn, ObjectID: 7, Object: &engine.Object{
+			ID: 7, Type: engine.Projectile, Size: engine.Vector{X: 2, Y: 2}, Position: engine.Vector{X: 50, Y: 60}, Bullet: true,
+		}},
+		{Sequence: 5, Tick: 12, Kind: engine.CommandRemove, ObjectID: 2},
+	}
+	decoded, err := engine.CommandsFromBytes(engine.CommandsToBytes(commands))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, commands) {
+		t.Errorf("Expected %+v, got %+v", commands, decoded)
+	}
+}
+
+func TestApplyCommands(t *testing.T) {
+	eng := newCommandEngine()
+	last, err := eng.ApplyCommands(1, engine.CommandsToBytes([]engine.Command{
+		{Sequence: 3, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+		{Sequence: 4, Kind: engine.CommandJump, ObjectID: 1, Speed: 300},
+		{Sequence: 5, Kind: engine.CommandJump, ObjectID: 2, Speed: 300}, // In the air, ignored
+		{Sequence: 6, Kind: engine.CommandImpulse, ObjectID: 2, Vector: engine.Vector{X: -10}, Damping: 0.5},
+		{Sequence: 7, Kind: engine.CommandSpawn, ObjectID: 3, Object: &engine.Object{ID: 3, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 5}}},
+		{Sequence: 8, Kind: engine.CommandRemove, ObjectID: 3},
+		{Sequence: 9, Kind: engine.CommandSpawn, ObjectID: 3, Object: &engine.Object{ID: 3, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 600, Y: 5}}},
+	}), engine.AllowAllCommands)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 9 {
+		t.Errorf("Expected last sequence 9, got %d", last)
+	}
+
+	if v := eng.GetObject(1).Velocity; v.X != 80 || v.Y != 300 {
+		t.Errorf("Expected the grounded creature to move and jump, got velocity %v", v)
+	}
+	if v := eng.GetObject(2).Velocity; v.Y != 0 {
+		t.Errorf("Expected the jump in the air to be ignored, got velocity %v", v)
+	}
+	if impulses := eng.GetObject(2).Impulses; impulses == nil || impulses.Direction.X != -10 || impulses.Damping != 0.5 {
+		t.Errorf("Expected the impulse to be added, got %+v", impulses)
+	}
+	if obj := eng.GetObject(3); obj == nil || obj.Position.X != 600 {
+		t.Errorf("Expected the item to be respawned at 600, got %+v", obj)
+	}
+}
+
+func TestApplyCommandsIsAtomic(t *testing.T) {
+	spawnExisting := &engine.Object{ID: 2, Type: engine.Item, Size: engine.Vector{X: 1, Y: 1}}
+	batches := map[string][]engine.Command{
+		"unknown object": {
+			{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandMove, ObjectID: 42, Vector: engine.Vector{X: 80}},
+		},
+		"removed object": {
+			{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandRemove, ObjectID: 2},
+			{Sequence: 3, Kind: engine.CommandImpulse, ObjectID: 2, Vector: engine.Vector{X: 1}},
+		},
+		"taken id": {
+			{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandSpawn, ObjectID: 2, Object: spawnExisting},
+		},
+		"sequence order": {
+			{Sequence: 2, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 90}},
+		},
+		"unknown type": {
+			{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandSpawn, ObjectID: 3, Object: &engine.Object{ID: 3, Type: engine.Item + 1, Size: engine.Vector{X: 1, Y: 1}}},
+		},
+		"non-finite": {
+			{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: math.NaN()}},
+		},
+	}
+	for name, commands := range batches {
+		t.Run(name, func(t *testing.T) {
+			eng := newCommandEngine()
+			if _, err := eng.ApplyCommands(1, engine.CommandsToBytes(commands), engine.AllowAllCommands); !errors.Is(err, engine.ErrInvalidCommand) {
+				t.Fatalf("Expected ErrInvalidCommand, got %v", err)
+			}
+			if v := eng.GetObject(1).Velocity; v.X != 0 {
+				t.Errorf("Expected no command to be applied, got velocity %v", v)
+			}
+			if eng.GetObject(2) == nil {
+				t.Error("Expected the creature not to be removed")
+			}
+		})
+	}
+
+	eng := newCommandEngine()
+	for _, data := range [][]byte{nil, {1, 2}, {0xff, 0xff, 0xff, 0x7f, 0, 0, 0, 0}} {
+		if _, err := eng.ApplyCommands(1, data, engine.AllowAllCommands); !errors.Is(err, engine.ErrInvalidCommand) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", data, err)
+		}
+	}
+}
+
+func TestApplyCommandsAuthorization(t *testing.T) {
+	// Every client controls the creature with its id and may spawn only items
+	own := func(clientID uint32, command *engine.Command, target *engine.Object) bool {
+		if command.Kind == engine.CommandSpawn {
+			return target.Type == engine.Item
+		}
+		return target.ID == int(clientID) || target.Type == engine.Item
+	}
+	item := &engine.Object{ID: 3, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 5}}
+	batches := map[string][]engine.Command{
+		"foreign object": {
+			{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandRemove, ObjectID: 2},
+		},
+		"terrain spawn": {
+			{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+			{Sequence: 2, Kind: engine.CommandSpawn, ObjectID: 3, Object: &engine.Object{ID: 3, Type: engine.Terrain, Size: engine.Vector{X: 100, Y: 10}}},
+		},
+	}
+	for name, commands := range batches {
+		t.Run(name, func(t *testing.T) {
+			eng := newCommandEngine()
+			if _, err := eng.ApplyCommands(1, engine.CommandsToBytes(commands), own); !errors.Is(err, engine.ErrUnauthorizedCommand) {
+				t.Fatalf("Expected ErrUnauthorizedCommand, got %v", err)
+			}
+			if v := eng.GetObject(1).Velocity; v.X != 0 || eng.GetObject(2) == nil || eng.GetObject(3) != nil {
+				t.Errorf("Expected no command to be applied, got velocity %v", v)
+			}
+		})
+	}
+
+	// The allowed batch is applied, the nil authorizer rejects everything
+	eng := newCommandEngine()
+	allowed := engine.CommandsToBytes([]engine.Command{
+		{Sequence: 1, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: 80}},
+		{Sequence: 2, Kind: engine.CommandSpawn, ObjectID: 3, Object: item},
+		{Sequence: 3, Kind: engine.CommandRemove, ObjectID: 3},
+	})
+	if _, err := eng.ApplyCommands(1, allowed, nil); !errors.Is(err, engine.ErrUnauthorizedCommand) {
+		t.Errorf("Expected the nil authorizer to reject the batch, got %v", err)
+	}
+	if _, err := eng.ApplyCommands(1, allowed, own); err != nil {
+		t.Errorf("Expected the owned commands to be applied, got %v", err)
+	}
+	if v := eng.GetObject(1).Velocity; v.X != 80 {
+		t.Errorf("Expected the owned creature to move, got velocity %v", v)
+	}
+}
+
+func TestCommandsRejectSelfReferencingImpulses(t *testing.T) {
+	data := engine.CommandsToBytes([]engine.Command{{Sequence: 1, Kind: engine.CommandSpawn, ObjectID: 3, Object: &engine.Object{
+		ID: 3, Type: engine.Item, Size: engine.Vector{X: 1, Y: 1},
+		Impulses: &engine.Impulse{Direction: engine.Vector{X: 1}, Damping: 0.5, Next: &engine.Impulse{Damping: 1}},
+	}}})
+
+	// Point the Next offset of the first impulse back to the impulse itself, the offsets wrap around
+	var command Game.ClientCommand
+	Game.GetRootAsCommandBatch(data, 0).Commands(&command, 0)
+	var body flatbuffers.Table
+	command.Command(&body)
+	var spawn Game.SpawnCommand
+	spawn.Init(body.Bytes, body.Pos)
+	impulse := spawn.Object(nil).Impulses(nil).Table()
+	next := impulse.Pos + flatbuffers.UOffsetT(impulse.Offset(8))
+	binary.LittleEndian.PutUint32(data[next:], uint32(impulse.Pos-next))
+
+	if commands, err := engine.CommandsFromBytes(data); !errors.Is(err, engine.ErrInvalidCommand) || commands != nil {
+		t.Errorf("Expected ErrInvalidCommand for the looped impulse chain, got %v %v", commands, err)
+	}
+	eng := newCommandEngine()
+	if _, err := eng.ApplyCommands(1, data, engine.AllowAllCommands); !errors.Is(err, engine.ErrInvalidCommand) || eng.GetObject(3) != nil {
+		t.Errorf("Expected the looped impulse chain to be rejected, got %v", err)
+	}
+}
+
+func TestApplyCommandsRejectsReplays(t *testing.T) {
+	eng := newCommandEngine()
+	move := func(sequence uint32, x float64) []byte {
+		return engine.CommandsToBytes([]engine.Command{{Sequence: sequence, Kind: engine.CommandMove, ObjectID: 1, Vector: engine.Vector{X: x}}})
+	}
+	if _, err := eng.ApplyCommands(1, move(5, 10), engine.AllowAllCommands); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same or an older batch of the client is rejected, other clients have their own sequences
+	for _, sequence := range []uint32{5, 4} {
+		if _, err := eng.ApplyCommands(1, move(sequence, 20), engine.AllowAllCommands); !errors.Is(err, engine.ErrInvalidCommand) {
+			t.Errorf("Expected ErrInvalidCommand for the replayed sequence %d, got %v", sequence, err)
+		}
+	}
+	if v := eng.GetObject(1).Velocity; v.X != 10 {
+		t.Errorf("Expected the replayed batches to be ignored, got velocity %v", v)
+	}
+	if _, err := eng.ApplyCommands(2, move(1, 30), engine.AllowAllCommands); err != nil {
+		t.Errorf("Expected the batch of another client to be applied, got %v", err)
+	}
+	if _, err := eng.ApplyCommands(1, move(6, 40), engine.AllowAllCommands); err != nil {
+		t.Errorf("Expected the newer batch to be applied, got %v", err)
+	}
+
+	// The reset client starts over
+	eng.ResetCommands(1)
+	if _, err := eng.ApplyCommands(1, move(1, 50), engine.AllowAllCommands); err != nil {
+		t.Errorf("Expected the reset client to start over, got %v", err)
+	}
+	if v := eng.GetObject(1).Velocity; v.X != 50 {
+		t.Errorf("Expected velocity 50, got %v", v)
+	}
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"math"
+	"sort"
+)
+
+// Check if the object needs continuous collision detection
+// Projectiles and bullet objects move fast enough to tunnel through thin objects
+func (obj *Object) continuous() bool {
+	return (obj.Type == Projectile || obj.Bullet) && !_isStatic(obj.Type)
+}
+
+// Remember the positions of the continuous objects before the integration
+// The starts map is reused between the ticks
+func _continuousStarts(world *World, starts map[int]Vector) map[int]Vector {
+	clear(starts)
+	for id, obj := range world.Objects {
+		if obj.continuous() {
+			if starts == nil {
+				starts = make(map[int]Vector)
+			}
+			starts[id] = obj.Position
+		}
+	}
+	return starts
+}
+
+// Sweep the continuous objects from their start positions to the integrated ones
+// and stop them at the earliest time of impact within the tick
+// Other objects are treated as standing still at their integrated positions,
+// objects overlapping at the start are left to the discrete resolution
+// The impacts are reported as contacts by the event stream
+func _resolveContinuous(world *World, starts map[int]Vector) {
+	if len(starts) == 0 {
+		return
+	}
+
+	ids := make([]int, 0, len(starts))
+	for id := range starts {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	index := world.spatialIndex()
+	var candidates []*Object
+	for _, id := range ids {
+		obj := world.Objects[id]
+		if obj == nil {
+			continue
+		}
+		start := starts[id]
+		displacement := Vector{X: obj.Position.X - start.X, Y: obj.Position.Y - start.Y}
+		length := displacement.magnitude()
+		if length < negligibleFloat {
+			continue // Too slow to tunnel through anything
+		}
+		dir := Vector{X: displacement.X / length, Y: displacement.Y / length}
+		half := Vector{X: obj.Size.X / 2, Y: obj.Size.Y / 2}
+
+		candidates = index.query(
+			math.Min(start.X, obj.Position.X)-half.X, math.Min(start.Y, obj.Position.Y)-half.Y,
+			math.Max(start.X, obj.Position.X)+half.X, math.Max(start.Y, obj.Position.Y)+half.Y,
+			candidates,
+		)
+
+		var target *Object
+		var normal Vector
+		toi := math.Inf(1)
+		for _, other := range candidates {
+			if other == obj || !_collides(obj.Type, other.Type) {
+				continue
+			}
+			minX, minY := other.positionLeftX()-half.X, other.positionBottomY()-half.Y
+			maxX, maxY := other.positionRightX()+half.X, other.positionTopY()+half.Y
+			if start.X > minX && start.X < maxX && start.Y > minY && start.Y < maxY {
+				continue // Already overlapping at the start
+			}
+			distance, n, ok := _rayBox(start, dir, length, minX, minY, maxX, maxY)
+			if !ok || distance > toi || distance == toi && other.ID > target.ID {
+				continue
+			}
+			target, normal, toi = other, n, distance
+		}
+		if target == nil {
+			continue
+		}
+
+		// Stop at the impact point, the rest of the movement is lost
+		obj.Position = Vector{X: start.X + dir.X*toi, Y: start.Y + dir.Y*toi}
+		if vn := obj.Velocity.dot(normal); vn < 0 {
+			restitution := _combineMaterial(obj.Restitution, target.Restitution)
+			obj.Velocity.X -= normal.X * vn * (1 + restitution)
+			obj.Velocity.Y -= normal.Y * vn * (1 + restitution)
+		}
+		_dampImpulsesAlong(obj, normal)
+		index.upsert(obj)
+	}
+}
@@ -0,0 +1,55 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestProjectileDoesNotTunnelThroughThinWall(t *testing.T) {
+	wall := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 10, Y: 200}, Position: engine.Vector{X: 250, Y: 500}}
+	arrow := &engine.Object{ID: 2, Type: engine.Projectile, Size: engine.Vector{X: 10, Y: 2}, Position: engine.Vector{X: 200, Y: 500}, Velocity: engine.Vector{X: 3000}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(wall, arrow), 0)
+	eng.Step(0.033) // 99 units per step, the wall is 10 units thick
+
+	if arrow.Position.X != 240 {
+		t.Errorf("Expected projectile to stop at the wall at 240, got %f", arrow.Position.X)
+	}
+	if arrow.Velocity.X != 0 {
+		t.Errorf("Expected projectile to stop, got velocity %f", arrow.Velocity.X)
+	}
+}
+
+func TestEarliestImpactWins(t *testing.T) {
+	far := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 4, Y: 200}, Position: engine.Vector{X: 280, Y: 500}}
+	hero := &engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 4, Y: 40}, Position: engine.Vector{X: 250, Y: 500}}
+	arrow := &engine.Object{ID: 3, Type: engine.Projectile, Size: engine.Vector{X: 10, Y: 2}, Position: engine.Vector{X: 200, Y: 500}, Velocity: engine.Vector{X: 3000}}
+
+	eng := &engine.Engine{}
+	eng.SetEventQueueSize(64)
+	eng.SetWorld(newTestWorld(far, hero, arrow), 0)
+	eng.Step(0.033)
+
+	if arrow.Position.X != 243 || hero.Position.X != 250 {
+		t.Errorf("Expected projectile to hit the creature first at 243, got %f", arrow.Position.X)
+	}
+	events := eventsOfType(eng.DrainEvents(), engine.ContactBegin)
+	if len(events) != 1 || events[0].ObjectID != hero.ID || events[0].OtherID != arrow.ID {
+		t.Errorf("Expected a single contact between the creature and the projectile, got %v", events)
+	}
+}
+
+func TestBulletFlagEnablesContinuousCollision(t *testing.T) {
+	wall := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 10, Y: 200}, Position: engine.Vector{X: 250, Y: 500}}
+	ball := &engine.Object{ID: 2, Type: engine.Item, Size: engine.Vector{X: 4, Y: 4}, Position: engine.Vector{X: 200, Y: 500}, Velocity: engine.Vector{X: 3000}, Bullet: true}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(wall, ball), 0)
+	eng.Step(0.033)
+
+	if ball.Position.X != 243 {
+		t.Errorf("Expected bullet item to stop at the wall at 243, got %f", ball.Position.X)
+	}
+}
@@ -0,0 +1,72 @@
+package engine
+
+// Bits of the object fields changed since the baseline, in the order of the Object fields
+type deltaField uint32
+
+const (
+	deltaType deltaField = 1 << iota
+	deltaClient
+	deltaSize
+	deltaVelocity
+	deltaPosition
+	deltaAnchor
+	deltaGravityFactor
+	deltaImpulses
+	deltaBullet
+	deltaRestitution
+	deltaFriction
+	deltaDrag
+)
+
+// Get the fields of the object changed since the baseline
+// Values are compared exactly, so the delta reconstructs the object without any loss
+func _diffObject(base *Object, obj *Object) deltaField {
+	var fields deltaField
+	if obj.Type != base.Type {
+		fields |= deltaType
+	}
+	if obj.Client != base.Client {
+		fields |= deltaClient
+	}
+	if obj.Size != base.Size {
+		fields |= deltaSize
+	}
+	if obj.Velocity != base.Velocity {
+		fields |= deltaVelocity
+	}
+	if obj.Position != base.Position {
+		fields |= deltaPosition
+	}
+	if obj.Anchor != base.Anchor {
+		fields |= deltaAnchor
+	}
+	if obj.GravityFactor != base.GravityFactor {
+		fields |= deltaGravityFactor
+	}
+	if !_impulsesEqual(obj.Impulses, base.Impulses) {
+		fields |= deltaImpulses
+	}
+	if obj.Bullet != base.Bullet {
+		fields |= deltaBullet
+	}
+	if obj.Restitution != base.Restitution {
+		fields |= deltaRestitution
+	}
+	if obj.Friction != base.Friction {
+		fields |= deltaFriction
+	}
+	if obj.Drag != base.Drag {
+		fields |= deltaDrag
+	}
+	return fields
+}
+
+// Check if two impulse lists are the same
+func _impulsesEqual(a, b *Impulse) bool {
+	for ; a != nil && b != nil; a, b = a.Next, b.Next {
+		if a.Direction != b.Direction || a.Damping != b.Damping {
+			return false
+		}
+	}
+	return a == nil && b == nil
+}
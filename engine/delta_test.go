@@ -0,0 +1,135 @@
+package engine_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/plugfox/slash-engine-go/engine"
+	"github.com/plugfox/slash-engine-go/generated/Game"
+)
+
+// packDelta serializes the world delta built with the generated object API.
+func packDelta(delta *Game.WorldDeltaT) []byte {
+	builder := flatbuffers.NewBuilder(256)
+	builder.Finish(delta.Pack(builder))
+	return builder.FinishedBytes()
+}
+
+func TestWorldDeltaRoundTrip(t *testing.T) {
+	baseline := newTestWorld(
+		&engine.Object{ID: 1, Type: engine.Terrain, Size: engine.Vector{X: 1000, Y: 20}, Position: engine.Vector{X: 500, Y: 10}},
+		&engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 100, Y: 40}, Impulses: &engine.Impulse{Direction: engine.Vector{Y: 100}, Damping: 0.8}},
+		&engine.Object{ID: 3, Type: engine.Projectile, Size: engine.Vector{X: 10, Y: 2}, Position: engine.Vector{X: 200, Y: 100}, Velocity: engine.Vector{X: 500}},
+	)
+	current := newTestWorld(
+		&engine.Object{ID: 1, Type: engine.Terrain, Size: engine.Vector{X: 1000, Y: 20}, Position: engine.Vector{X: 500, Y: 10}},
+		&engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 110, Y: 40}, Velocity: engine.Vector{X: 100}, Friction: 1},
+		&engine.Object{ID: 4, Type: engine.Item, Size: engine.Vector{X: 8, Y: 8}, Position: engine.Vector{X: 300, Y: 24}, Client: true},
+	)
+	current.Gravity = 9.8
+
+	data := current.DeltaToBytes(baseline)
+	restored, err := engine.WorldFromDelta(baseline, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Gravity != current.Gravity || restored.Boundary != current.Boundary {
+		t.Errorf("Expected world settings to be restored, got %f and %v", restored.Gravity, restored.Boundary)
+	}
+	if !reflect.DeepEqual(restored.Objects, current.Objects) {
+		t.Errorf("Expected objects to be restored from the delta, got %v", restored.Objects)
+	}
+	if baseline.Objects[2].Position.X != 100 || baseline.Objects[2].Impulses == nil || baseline.Objects[3] == nil {
+		t.Errorf("Expected baseline to stay unchanged")
+	}
+
+	// Nothing changed, the delta is almost empty
+	if empty := current.DeltaToBytes(current); len(empty) >= len(data) {
+		t.Errorf("Expected delta of the same world to be smaller, got %d bytes", len(empty))
+	}
+}
+
+func TestWorldDeltaIsSmallerThanFullSnapshot(t *testing.T) {
+	baseline := newCrowdedWorld(1000, 100)
+	current, err := engine.WorldFromBytes(baseline.ToBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, obj := range current.Objects {
+		if id%10 == 0 {
+			obj.Position.X++
+		}
+	}
+
+	full, delta := current.ToBytes(), current.DeltaToBytes(baseline)
+	if len(delta)*4 > len(full) {
+		t.Errorf("Expected delta of 10%% moved objects to be much smaller than %d bytes, got %d", len(full), len(delta))
+	}
+	restored, err := engine.WorldFromDelta(baseline, delta)
+	if err != nil || !reflect.DeepEqual(restored.Objects, current.Objects) {
+		t.Errorf("Expected objects to be restored from the delta, got %v", err)
+	}
+}
+
+func TestWorldFromDeltaRejectsInvalidData(t *testing.T) {
+	baseline := newTestWorld(&engine.Object{ID: 1, Type: engine.Item, Size: engine.Vector{X: 8, Y: 8}, Position: engine.Vector{X: 100, Y: 4}})
+	cases := map[string][]byte{
+		"empty":        nil,
+		"short":        {1, 0},
+		"root out":     {0xff, 0xff, 0, 0, 0, 0, 0, 0},
+		"unknown id":   packDelta(&Game.WorldDeltaT{Changed: []*Game.ObjectDeltaT{{ID: 2, Fields: 1 << 4, Position: &Game.VectorT{X: 1}}}}),
+		"duplicate":    packDelta(&Game.WorldDeltaT{Added: []*Game.ObjectT{{ID: 2}}, Changed: []*Game.ObjectDeltaT{{ID: 2}}}),
+		"unknown type": packDelta(&Game.WorldDeltaT{Changed: []*Game.ObjectDeltaT{{ID: 1, Fields: 1, Type: Game.ObjectType(42)}}}),
+		"non-finite":   packDelta(&Game.WorldDeltaT{Changed: []*Game.ObjectDeltaT{{ID: 1, Velocity: &Game.VectorT{Y: math.Inf(1)}}}}),
+	}
+	for name, data := range cases {
+		if world, err := engine.WorldFromDelta(baseline, data); !errors.Is(err, engine.ErrInvalidWorld) || world != nil {
+			t.Errorf("%s: expected ErrInvalidWorld, got %v %v", name, world, err)
+		}
+	}
+
+	// Every truncation of a valid delta is rejected without panicking
+	current := newCrowdedWorld(20, 10)
+	data := current.DeltaToBytes(newCrowdedWorld(10, 5))
+	for n := 0; n < len(data); n++ {
+		if _, err := engine.WorldFromDelta(baseline, data[:n]); err == nil {
+			t.Fatalf("Expected the delta truncated to %d of %d bytes to be rejected", n, len(data))
+		}
+	}
+}
+
+func FuzzWorldFromDelta(f *testing.F) {
+	baseline := newCrowdedWorld(5, 5)
+	current, err := engine.WorldFromBytes(baseline.ToBytes())
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, obj := range current.Objects {
+		obj.Position.X++
+	}
+	f.Add([]byte{})
+	f.Add(current.DeltaToBytes(baseline))
+	f.Add(newCrowdedWorld(8, 2).DeltaToBytes(baseline))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		world, err := engine.WorldFromDelta(baseline, data)
+		if err != nil {
+			if !(errors.Is(err, engine.ErrInvalidWorld) || errors.Is(err, engine.ErrIncompatibleFormat)) || world != nil {
+				t.Fatalf("Expected a nil world and ErrInvalidWorld or ErrIncompatibleFormat, got %v %v", world, err)
+			}
+			return
+		}
+		// An accepted delta survives a round trip
+		again, err := engine.WorldFromDelta(baseline, world.DeltaToBytes(baseline))
+		if err != nil {
+			t.Fatalf("Expected the accepted delta to round trip, got %v", err)
+		}
+		if len(again.Objects) != len(world.Objects) {
+			t.Fatalf("Expected %d objects after the round trip, got %d", len(world.Objects), len(again.Objects))
+		}
+	})
+}
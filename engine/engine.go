@@ -1,188 +1,233 @@
 package engine
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Engine represents the game physics controller
 type Engine struct {
-	world        *World        // Game world instance
-	mutex        sync.RWMutex  // Game engine mutex
-	running      bool          // Running flag
-	stopChannel  chan struct{} // Stop channel
-	updateSignal chan struct{} // Update signal
-	updateTicker *time.Ticker  // Update ticker
-	lastUpdate   time.Time     // Last update time
+	world      *World       // Game world instance
+	mutex      sync.RWMutex // Game engine mutex, take the write lock with lock()
+	loop       *runLoop     // Last update loop started by Run, nil if never started
+	lastUpdate time.Time    // Last update time
+	tick       uint64       // Number of simulation steps since the world was created
+	integrator Integrator   // Numerical integration method
+	paused     bool         // The loop started by Run doesn't update the world
+	timeScale  float64      // Simulated time per real second in the loop, 0 means 1
+	maxElapsed float64      // Longest time advanced by a single update of the loop (seconds), 0 means the default
+
+	workers int              // Number of goroutines integrating the objects, 0 or 1 is serial
+	pool    *workerPool      // Goroutines integrating the objects, nil for the serial integration
+	chunks  []*Object        // Objects split between the workers, reused between the ticks
+	moved   [][]spatialEntry // New cells of the objects moved by each worker, reused between the ticks
+
+	fixedStep   float64        // Fixed simulation step in seconds, 0 means variable step
+	maxSubsteps int            // Maximum number of fixed steps per update
+	accumulator float64        // Elapsed time not simulated yet in the fixed-step mode
+	alpha       float64        // Interpolation fraction between the previous and current state
+	previous    map[int]Vector // Object positions before the last fixed step
+	starts      map[int]Vector // Positions of the continuous objects before the integration
+
+	subscriptions  []*subscription       // Event handlers
+	pendingEvents  []Event               // Events not dispatched to the handlers yet
+	eventQueue     eventRing             // Events for DrainEvents and PollEvents
+	eventQueueSize int                   // Capacity of the event queue, 0 disables the queue
+	contacts       map[contactKey]Vector // Touching pairs after the last tick
+	grounded       map[int]bool          // Objects resting on something after the last tick
+	outside        map[int]bool          // Objects beyond the boundaries after the last tick
+
+	inputs        []Input   // Predicted inputs not acknowledged by the server yet
+	inputSequence uint32    // Sequence number of the last recorded input
+	ackTick       uint64    // Tick of the last acknowledged input
+	durations     []float64 // Durations of the ticks since durationsFrom, for the replay
+	durationsFrom uint64    // Tick number the first recorded duration starts from
+
+	commandSequences map[uint32]uint32 // Sequence of the last applied command batch by client
+
+	snapshots          []snapshot // Server snapshots of the remote objects sorted by time
+	interpolationSet   bool       // SetInterpolation was called, the defaults are used otherwise
+	snapshotBufferSize int        // Number of the snapshots to keep
+	interpolationDelay float64    // Delay of the rendered remote objects (seconds)
+	maxExtrapolation   float64    // Limit of the extrapolation past the newest snapshot (seconds)
+
+	simulationTime float64        // Simulated seconds since the world was created
+	history        []historyFrame // Ring buffer of the last ticks for the lag compensation
+	historyStart   int            // Index of the oldest frame in the ring buffer
+	historySize    int            // Number of ticks to keep, 0 disables the history
+
+	preTickHooks  []*tickHook // Hooks called before every tick
+	postTickHooks []*tickHook // Hooks called after every tick
+
+	published atomic.Pointer[publishedWorld] // Read-only copy of the world shared by the readers until the next write lock
 }
 
-// Get the world instance, can be nil
+// Get a snapshot of the world, can be nil
+// The snapshot is a private copy, so it's safe to read while the world is updating,
+// changes to it don't affect the engine, use the engine methods or SetWorld instead
+// Read-only callers can use the cheaper GetWorldView
 func (engine *Engine) GetWorld() *World {
-	engine.mutex.RLock()
-	defer engine.mutex.RUnlock()
-	return engine.getWorld()
+	world, _, _ := engine.snapshot()
+	if world == nil {
+		return nil
+	}
+	return world.Clone()
 }
 
-// Stop and clear the world
+// Stop the update loop started by Run and wait until both of its goroutines exit
+// Don't call it from the event handlers run by the loop, it would wait for itself,
+// cancel the context passed to RunContext instead
 func (engine *Engine) Stop() {
-	engine.mutex.Lock()
-	defer engine.mutex.Unlock()
-	if !engine.running {
+	engine.mutex.RLock()
+	loop := engine.loop
+	engine.mutex.RUnlock()
+	if loop == nil {
 		return
 	}
-	engine.running = false
-	close(engine.stopChannel)  // Сигнал для завершения всех горутин
-	engine.updateTicker.Stop() // Остановка таймера
+	loop.cancel(nil)
+	<-loop.done
 }
 
-// Run the world update loop
+// Run the world update loop in the background, see RunContext
+// Panics on a non-positive tickMS, does nothing without a world or if already running
 func (engine *Engine) Run(tickMS float64) {
-	if tickMS <= 0 {
-		panic(fmt.Errorf("invalid tickMS: %v", tickMS))
-	}
-
-	engine.mutex.Lock()
-	if engine.running || engine.world == nil {
-		engine.mutex.Unlock()
-		return
+	if err := engine.RunContext(context.Background(), tickMS); errors.Is(err, ErrInvalidTick) {
+		panic(err)
 	}
-	engine.running = true
-	engine.stopChannel = make(chan struct{})     // Создаём новый канал при запуске
-	engine.updateSignal = make(chan struct{}, 1) // Обеспечиваем буфер
-	engine.lastUpdate = time.Now()
-	engine.updateTicker = time.NewTicker(time.Duration(tickMS) * time.Millisecond)
-	engine.mutex.Unlock()
-
-	// Горутина для обработки сигналов таймера
-	go func() {
-		defer engine.updateTicker.Stop()
-		for {
-			select {
-			case <-engine.updateTicker.C:
-				select {
-				case engine.updateSignal <- struct{}{}:
-				default: // Skip if already updating
-				}
-			case <-engine.stopChannel:
-				return // Завершаем выполнение
-			}
-		}
-	}()
-
-	// Горутина для обработки обновлений мира
-	go func() {
-		for {
-			select {
-			case <-engine.updateSignal:
-				func() {
-					engine.mutex.Lock()
-					defer engine.mutex.Unlock()
-					now := time.Now()                               // Current time
-					elapsed := now.Sub(engine.lastUpdate).Seconds() // Elapsed time since last update
-					engine.update(elapsed)                          // Update the world
-					engine.lastUpdate = now                         // Set last update time
-				}()
-			case <-engine.stopChannel:
-				return // Завершаем выполнение
-			}
-		}
-	}()
 }
 
 // Create a new world instance
+// The returned world is the live world owned by the engine, not a copy:
+// don't read or change it while the engine may be updating it, e.g. once it's running,
+// changes made through it are seen by GetWorld, GetWorldView and GetWorldBytes
+// only after the next engine call changing the world, use the engine methods instead
 func (engine *Engine) CreateWorld(gravity float64, boundary Vector) *World {
-	engine.mutex.Lock()
+	engine.lock()
 	defer engine.mutex.Unlock()
 	world := &World{
 		Gravity:  gravity,
 		Boundary: boundary,
 		Objects:  make(map[int]*Object),
 	}
-	engine.world = world
+	engine.setWorld(world)
+	engine.tick = 0
+	engine.simulationTime = 0
+	engine.resetTimestep()
+	engine.resetEvents()
+	engine.resetPrediction()
+	engine.snapshots = nil
+	engine.resetHistory()
 	return world
 }
 
+// Advance the world synchronously by exactly dt seconds as a single tick
+// Doesn't depend on Run, goroutines or timers, so it can be used
+// for headless servers, tests, replays and lockstep simulations
+// Returns the new tick number, non-positive dt doesn't advance the world
+func (engine *Engine) Step(dt float64) uint64 {
+	defer engine.dispatchEvents()
+	engine.lock()
+	defer engine.mutex.Unlock()
+	return engine.step(dt)
+}
+
+// Advance the world synchronously by n ticks of dt seconds each
+// Returns the new tick number
+func (engine *Engine) StepN(n int, dt float64) uint64 {
+	defer engine.dispatchEvents()
+	engine.lock()
+	defer engine.mutex.Unlock()
+	for range n {
+		engine.step(dt)
+	}
+	return engine.tick
+}
+
+// Get the current tick number
+func (engine *Engine) GetTick() uint64 {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return engine.tick
+}
+
 // Set the world instance
 // RTT (round-trip time) is the ping-pong time between client and server
 // RTT is used for extrapolation to predict object positions
+// The engine takes the world and its objects as they are, without copying:
+// the caller's pointers stay live, so the same rules as for CreateWorld apply to them
 func (engine *Engine) SetWorld(world *World, rtt float64) {
-	engine.mutex.Lock()
+	defer engine.dispatchEvents()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	engine.world = world
+	engine.emitReplaced(engine.world, world)
+	engine.setWorld(world)
+	engine.resetTimestep()
+	engine.resetHistory()
 	if rtt > 0 {
 		engine.update(rtt) // Extrapolate object positions based on RTT
 	}
 	engine.lastUpdate = time.Now() // Set last update time
 }
 
-// Get an object or particle by id
+// Get a copy of an object or particle by id, nil if there is no such object
+// Changes to the copy don't affect the engine, use UpsertObject instead
 func (engine *Engine) GetObject(id int) *Object {
 	engine.mutex.RLock()
 	defer engine.mutex.RUnlock()
-	return engine.getObject(id)
+	obj := engine.getObject(id)
+	if obj == nil {
+		return nil
+	}
+	return obj.clone()
 }
 
 // Upsert an object to the world
+// The engine keeps the object itself, not a copy, so the pointer stays live, see CreateWorld
 func (engine *Engine) UpsertObject(obj *Object) {
-	engine.mutex.Lock()
+	defer engine.dispatchEvents()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	world := engine.getWorld()
-	if world != nil {
-		world.Objects[obj.ID] = obj
-	}
+	engine.upsertObject(obj)
 }
 
 // Upsert objects to the world
+// The engine keeps the objects themselves, not copies, so the pointers stay live, see CreateWorld
 func (engine *Engine) UpsertObjects(objects []*Object) {
-	engine.mutex.Lock()
+	defer engine.dispatchEvents()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	world := engine.getWorld()
-	if world != nil {
-		for _, obj := range objects {
-			world.Objects[obj.ID] = obj
-		}
+	for _, obj := range objects {
+		engine.upsertObject(obj)
 	}
 }
 
 // Add an impulse to an object
 func (engine *Engine) AddImpulse(id int, direction Vector, damping float64) {
-	engine.mutex.Lock()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	obj := engine.getObject(id)
-	if obj != nil {
-		newImpulse := &Impulse{
-			Direction: direction,
-			Damping:   damping,
-			Next:      obj.Impulses,
-		}
-		obj.Impulses = newImpulse
-	}
+	engine.addImpulse(id, direction, damping)
 }
 
 // Set the velocity of an object
 func (engine *Engine) SetVelocity(id int, velocity Vector) {
-	engine.mutex.Lock()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	obj := engine.getObject(id)
-	if obj != nil {
-		obj.Velocity = velocity
-	}
+	engine.setVelocity(id, velocity)
 }
 
 // Set the position of an object
 func (engine *Engine) SetPosition(id int, position Vector) {
-	engine.mutex.Lock()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	obj := engine.getObject(id)
-	if obj != nil {
-		obj.Position = position
-	}
+	engine.setPosition(id, position)
 }
 
 // Set the anchor of an object
 func (engine *Engine) SetAnchor(id int, anchor Vector) {
-	engine.mutex.Lock()
+	engine.lock()
 	defer engine.mutex.Unlock()
 	obj := engine.getObject(id)
 	if obj != nil {
@@ -192,28 +237,57 @@ func (engine *Engine) SetAnchor(id int, anchor Vector) {
 
 // Remove object by ID
 func (engine *Engine) RemoveObject(id int) {
-	engine.mutex.Lock()
+	defer engine.dispatchEvents()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	world := engine.getWorld()
-	if world != nil {
-		delete(world.Objects, id)
-	}
+	engine.removeObject(id)
 }
 
 // Remove objects by IDs
 func (engine *Engine) RemoveObjects(ids []int) {
-	engine.mutex.Lock()
+	defer engine.dispatchEvents()
+	engine.lock()
 	defer engine.mutex.Unlock()
-	world := engine.getWorld()
-	if world != nil {
-		for _, id := range ids {
-			delete(world.Objects, id)
-		}
+	for _, id := range ids {
+		engine.removeObject(id)
 	}
 }
 
 // -- Internal methods -- //
 
+// Take the write lock to change the world, the published copy of the world is dropped
+// Calls changing only the engine settings or queues take engine.mutex.Lock instead,
+// so the readers keep sharing the published copy
+func (engine *Engine) lock() {
+	engine.mutex.Lock()
+	engine.published.Store(nil)
+}
+
+// Advance the world by dt seconds as a single tick and return the tick number
+func (engine *Engine) step(dt float64) uint64 {
+	if dt <= 0 || engine.world == nil {
+		return engine.tick
+	}
+	engine.recordDuration(dt)
+	engine.runHooks(engine.preTickHooks, engine.tick+1, dt)
+	engine.update(dt)
+	engine.tick++
+	engine.simulationTime += dt
+	engine.runHooks(engine.postTickHooks, engine.tick, dt)
+	engine.recordHistory()
+	engine.detectEvents()
+	return engine.tick
+}
+
+// Replace the world and bring its spatial index up to date,
+// so the queries under the read lock never have to build or sync it
+func (engine *Engine) setWorld(world *World) {
+	engine.world = world
+	if world != nil {
+		world.spatialIndex().sync(world.Objects)
+	}
+}
+
 // Get the world instance, can be nil
 func (engine *Engine) getWorld() *World {
 	return engine.world
@@ -227,3 +301,58 @@ func (engine *Engine) getObject(id int) *Object {
 	}
 	return world.Objects[id]
 }
+
+// Upsert the object to the world
+func (engine *Engine) upsertObject(obj *Object) {
+	world := engine.getWorld()
+	if world == nil {
+		return
+	}
+	if _, ok := world.Objects[obj.ID]; !ok {
+		engine.emitAdded(obj)
+	}
+	world.Objects[obj.ID] = obj
+	world.indexUpsert(obj)
+}
+
+// Remove the object from the world
+func (engine *Engine) removeObject(id int) {
+	world := engine.getWorld()
+	if world == nil {
+		return
+	}
+	if obj, ok := world.Objects[id]; ok {
+		engine.emitRemoved(obj)
+	}
+	delete(world.Objects, id)
+	world.indexRemove(id)
+}
+
+// Add an impulse to the object
+func (engine *Engine) addImpulse(id int, direction Vector, damping float64) {
+	obj := engine.getObject(id)
+	if obj != nil {
+		obj.Impulses = &Impulse{
+			Direction: direction,
+			Damping:   damping,
+			Next:      obj.Impulses,
+		}
+	}
+}
+
+// Set the velocity of the object
+func (engine *Engine) setVelocity(id int, velocity Vector) {
+	obj := engine.getObject(id)
+	if obj != nil {
+		obj.Velocity = velocity
+	}
+}
+
+// Set the position of the object and move it in the index
+func (engine *Engine) setPosition(id int, position Vector) {
+	obj := engine.getObject(id)
+	if obj != nil {
+		obj.Position = position
+		engine.world.indexUpsert(obj)
+	}
+}
@@ -55,3 +55,25 @@ func TestWorldToBytes(t *testing.T) {
 		}
 	})
 }
+
+func TestStepAdvancesTicks(t *testing.T) {
+	runWithTimeout(t, func(t *testing.T) {
+		eng := &engine.Engine{}
+		if tick := eng.Step(0.016); tick != 0 {
+			t.Errorf("Expected no ticks without a world, got %d", tick)
+		}
+
+		eng.CreateWorld(0, engine.Vector{X: 1000, Y: 1000})
+		eng.UpsertObject(&engine.Object{ID: 1, Type: engine.Effect, Size: engine.Vector{X: 1, Y: 1}, Position: engine.Vector{X: 0, Y: 500}, Velocity: engine.Vector{X: 10}})
+
+		if tick := eng.Step(0.5); tick != 1 {
+			t.Errorf("Expected tick 1, got %d", tick)
+		}
+		if tick := eng.StepN(10, 0.1); tick != 11 || eng.GetTick() != 11 {
+			t.Errorf("Expected tick 11, got %d", tick)
+		}
+		if x := eng.GetObject(1).Position.X; x != 15 {
+			t.Errorf("Expected position 15 after 1.5 seconds, got %f", x)
+		}
+	})
+}
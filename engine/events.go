@@ -0,0 +1,412 @@
+package engine
+
+import (
+	"math"
+	"sort"
+)
+
+type EventType int
+
+const (
+	// ContactBegin is emitted when two colliding objects start touching
+	// ObjectID is the lower ID of the pair, Normal points towards ObjectID
+	ContactBegin EventType = iota
+
+	// ContactEnd is emitted when two colliding objects stop touching
+	// or one of them is removed from the world
+	ContactEnd
+
+	// Landed is emitted when a creature, item or projectile comes to rest
+	// on the floor or on top of another object (OtherID, if HasOther)
+	Landed
+
+	// LeftBounds is emitted when the object's box leaves the world boundaries
+	LeftBounds
+
+	// ObjectAdded is emitted when a new object is added to the world
+	ObjectAdded
+
+	// ObjectRemoved is emitted when an object is removed from the world
+	ObjectRemoved
+)
+
+// Distance at which the objects are considered touching
+const contactTolerance = negligibleFloat
+
+// Event represents something that happened in the world
+type Event struct {
+	Type     EventType // Type of the event
+	Tick     uint64    // Tick number when the event happened
+	ObjectID int       // Object the event is about
+	OtherID  int       // Other object of the contact or the landing
+	HasOther bool      // OtherID is set (false for landing on the floor)
+	Normal   Vector    // Contact normal pointing towards the object
+	Position Vector    // Object position when the event happened
+}
+
+// Subscription of an event handler
+type subscription struct {
+	handler func(Event)
+}
+
+// Pair of touching objects, a has the lower ID
+type contactKey struct {
+	a, b int
+}
+
+// Ring buffer of the queued events, the oldest event is overwritten when it's full
+type eventRing struct {
+	buffer []Event // Slots of the ring, grown up to the queue capacity
+	start  int     // Index of the oldest event
+	count  int     // Number of the queued events
+}
+
+// Subscribe to the world events
+// The handler is called synchronously after each tick (or engine call)
+// outside of the engine lock, so it can call the engine methods
+// Returns the function to unsubscribe the handler
+func (engine *Engine) Subscribe(handler func(Event)) func() {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	sub := &subscription{handler: handler}
+	engine.subscriptions = append(engine.subscriptions, sub)
+	return func() {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+		for i, other := range engine.subscriptions {
+			if other == sub {
+				engine.subscriptions = append(engine.subscriptions[:i:i], engine.subscriptions[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Enable the event queue for DrainEvents and PollEvents with the capacity,
+// the oldest events are dropped when the queue is full
+// The queue is disabled by default, so the events aren't tracked unless someone reads them,
+// zero or negative capacity disables the queue and drops the queued events
+func (engine *Engine) SetEventQueueSize(capacity int) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.eventQueueSize = max(capacity, 0)
+	engine.eventQueue.resize(engine.eventQueueSize)
+}
+
+// Take all queued events in the order they happened
+func (engine *Engine) DrainEvents() []Event {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.eventQueue.drain()
+}
+
+// Move up to len(events) oldest queued events to the buffer
+// Returns the number of events written
+func (engine *Engine) PollEvents(events []Event) int {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.eventQueue.pop(events)
+}
+
+// -- Internal methods -- //
+
+// Events are tracked only if there are subscribers or the queue is enabled
+func (engine *Engine) eventsEnabled() bool {
+	return len(engine.subscriptions) > 0 || engine.eventQueueSize > 0
+}
+
+// Emit an event to the queue and to the subscribers
+// Subscribers are notified later by dispatchEvents, outside of the lock
+func (engine *Engine) emit(event Event) {
+	event.Tick = engine.tick
+	if engine.eventQueueSize > 0 {
+		engine.eventQueue.push(event, engine.eventQueueSize)
+	}
+	if len(engine.subscriptions) > 0 {
+		engine.pendingEvents = append(engine.pendingEvents, event)
+	}
+}
+
+// Add the event to the ring, overwriting the oldest one if the ring holds the capacity events
+func (ring *eventRing) push(event Event, capacity int) {
+	if ring.count == len(ring.buffer) && len(ring.buffer) < capacity {
+		ring.grow(min(max(2*len(ring.buffer), 16), capacity))
+	}
+	if ring.count == len(ring.buffer) {
+		ring.buffer[ring.start] = event // Drop the oldest event
+		ring.start = (ring.start + 1) % len(ring.buffer)
+		return
+	}
+	ring.buffer[(ring.start+ring.count)%len(ring.buffer)] = event
+	ring.count++
+}
+
+// Move up to len(events) oldest events to the buffer and return their number
+func (ring *eventRing) pop(events []Event) int {
+	n := min(len(events), ring.count)
+	if n == 0 {
+		return 0
+	}
+	first := copy(events[:n], ring.buffer[ring.start:])
+	copy(events[first:n], ring.buffer)
+	ring.start = (ring.start + n) % len(ring.buffer)
+	ring.count -= n
+	if ring.count == 0 {
+		ring.start = 0
+	}
+	return n
+}
+
+// Take all events in the order they were pushed, nil if there are none
+func (ring *eventRing) drain() []Event {
+	if ring.count == 0 {
+		return nil
+	}
+	events := make([]Event, ring.count)
+	ring.pop(events)
+	return events
+}
+
+// Reallocate the ring with the size slots, keeping the events in order
+func (ring *eventRing) grow(size int) {
+	buffer := make([]Event, size)
+	count := ring.pop(buffer)
+	ring.buffer, ring.start, ring.count = buffer, 0, count
+}
+
+// Change the capacity of the ring keeping the newest events, zero frees the ring
+func (ring *eventRing) resize(capacity int) {
+	if capacity == 0 {
+		*ring = eventRing{}
+		return
+	}
+	if drop := ring.count - capacity; drop > 0 {
+		ring.start = (ring.start + drop) % len(ring.buffer)
+		ring.count = capacity
+	}
+	if len(ring.buffer) > capacity {
+		ring.grow(capacity)
+	}
+}
+
+// Emit the added event for the object
+func (engine *Engine) emitAdded(obj *Object) {
+	if engine.eventsEnabled() {
+		engine.emit(Event{Type: ObjectAdded, ObjectID: obj.ID, Position: obj.Position})
+	}
+}
+
+// Emit the removed event for the object and forget its tracked state
+func (engine *Engine) emitRemoved(obj *Object) {
+	if !engine.eventsEnabled() {
+		return
+	}
+	engine.emit(Event{Type: ObjectRemoved, ObjectID: obj.ID, Position: obj.Position})
+	delete(engine.grounded, obj.ID)
+	delete(engine.outside, obj.ID)
+}
+
+// Emit the removed and added events for the objects of the replaced world
+func (engine *Engine) emitReplaced(prev *World, next *World) {
+	if !engine.eventsEnabled() || prev == next {
+		return
+	}
+	if prev != nil {
+		for _, id := range sortedObjectIDs(prev.Objects) {
+			if next == nil || next.Objects[id] == nil {
+				engine.emitRemoved(prev.Objects[id])
+			}
+		}
+	}
+	if next != nil {
+		for _, id := range sortedObjectIDs(next.Objects) {
+			if prev == nil || prev.Objects[id] == nil {
+				engine.emitAdded(next.Objects[id])
+			}
+		}
+	}
+}
+
+// Notify the subscribers about the pending events
+// Must be called without holding the engine lock
+func (engine *Engine) dispatchEvents() {
+	engine.mutex.Lock()
+	events := engine.pendingEvents
+	engine.pendingEvents = nil
+	subscriptions := engine.subscriptions
+	engine.mutex.Unlock()
+
+	for _, event := range events {
+		for _, sub := range subscriptions {
+			sub.handler(event)
+		}
+	}
+}
+
+// Detect contacts, landings and objects leaving the boundaries after the tick
+// and emit the events for the changes since the previous tick
+func (engine *Engine) detectEvents() {
+	world := engine.world
+	if world == nil || !engine.eventsEnabled() {
+		return
+	}
+
+	index := world.spatialIndex()
+	contacts := make(map[contactKey]Vector, len(engine.contacts))
+	grounded := make(map[int]*Object, len(engine.grounded)) // Object ID -> support, nil for the floor
+	outside := make(map[int]bool, len(engine.outside))
+	var candidates []*Object
+
+	for _, id := range sortedObjectIDs(world.Objects) {
+		obj := world.Objects[id]
+		if _isBeyondBoundary(obj, world.Boundary) {
+			outside[id] = true
+		}
+		if _isStatic(obj.Type) || obj.Type == Effect || obj.Type == Other {
+			continue // Statics are checked from the other side, effects pass through
+		}
+		if obj.onTheFloor() && !obj.movingUpward() {
+			grounded[id] = nil
+		}
+
+		candidates = index.query(
+			obj.positionLeftX()-contactTolerance, obj.positionBottomY()-contactTolerance,
+			obj.positionRightX()+contactTolerance, obj.positionTopY()+contactTolerance,
+			candidates,
+		)
+		for _, other := range candidates {
+			if other == obj || !_isStatic(other.Type) && other.ID < obj.ID || !_collides(obj.Type, other.Type) {
+				continue
+			}
+			normal, ok := _touching(obj, other)
+			if !ok {
+				continue
+			}
+			key, keyNormal := contactKey{obj.ID, other.ID}, normal
+			if other.ID < obj.ID {
+				key, keyNormal = contactKey{other.ID, obj.ID}, Vector{X: -normal.X, Y: -normal.Y}
+			}
+			contacts[key] = keyNormal
+
+			// Supported from below by the other object, or supporting it
+			if normal.Y > 0 && !obj.movingUpward() {
+				grounded[obj.ID] = other
+			} else if normal.Y < 0 && !_isStatic(other.Type) && !other.movingUpward() {
+				grounded[other.ID] = obj
+			}
+		}
+	}
+
+	engine.emitContactChanges(world, contacts)
+	engine.emitLandings(world, grounded)
+	engine.emitLeftBounds(world, outside)
+}
+
+// Emit begin and end events for the contacts changed since the previous tick
+func (engine *Engine) emitContactChanges(world *World, contacts map[contactKey]Vector) {
+	for _, key := range _sortedContactKeys(contacts) {
+		if _, ok := engine.contacts[key]; !ok {
+			obj := world.Objects[key.a]
+			engine.emit(Event{Type: ContactBegin, ObjectID: key.a, OtherID: key.b, HasOther: true, Normal: contacts[key], Position: obj.Position})
+		}
+	}
+	for _, key := range _sortedContactKeys(engine.contacts) {
+		if _, ok := contacts[key]; !ok {
+			event := Event{Type: ContactEnd, ObjectID: key.a, OtherID: key.b, HasOther: true, Normal: engine.contacts[key]}
+			if obj := world.Objects[key.a]; obj != nil {
+				event.Position = obj.Position
+			}
+			engine.emit(event)
+		}
+	}
+	engine.contacts = contacts
+}
+
+// Emit landed events for the objects grounded since the previous tick
+func (engine *Engine) emitLandings(world *World, grounded map[int]*Object) {
+	ids := make([]int, 0, len(grounded))
+	for id := range grounded {
+		if !engine.grounded[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		event := Event{Type: Landed, ObjectID: id, Normal: Vector{Y: 1}, Position: world.Objects[id].Position}
+		if support := grounded[id]; support != nil {
+			event.OtherID, event.HasOther = support.ID, true
+		}
+		engine.emit(event)
+	}
+
+	engine.grounded = make(map[int]bool, len(grounded))
+	for id := range grounded {
+		engine.grounded[id] = true
+	}
+}
+
+// Emit left-bounds events for the objects that left the boundaries since the previous tick
+func (engine *Engine) emitLeftBounds(world *World, outside map[int]bool) {
+	ids := make([]int, 0, len(outside))
+	for id := range outside {
+		if !engine.outside[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		engine.emit(Event{Type: LeftBounds, ObjectID: id, Position: world.Objects[id].Position})
+	}
+	engine.outside = outside
+}
+
+// Reset the tracked contacts, landings and boundaries
+func (engine *Engine) resetEvents() {
+	engine.contacts = nil
+	engine.grounded = nil
+	engine.outside = nil
+}
+
+// Check if two objects touch or overlap, and get the contact normal pointing towards a
+// Objects touching only by the corners are not in contact
+func _touching(a, b *Object) (Vector, bool) {
+	overlapX := math.Min(a.positionRightX(), b.positionRightX()) - math.Max(a.positionLeftX(), b.positionLeftX())
+	overlapY := math.Min(a.positionTopY(), b.positionTopY()) - math.Max(a.positionBottomY(), b.positionBottomY())
+	if overlapX < -contactTolerance || overlapY < -contactTolerance {
+		return Vector{}, false // Separated
+	}
+	if overlapX <= contactTolerance && overlapY <= contactTolerance {
+		return Vector{}, false // Touching by the corners
+	}
+	if overlapX < overlapY {
+		if a.Position.X < b.Position.X {
+			return Vector{X: -1}, true
+		}
+		return Vector{X: 1}, true
+	}
+	if a.Position.Y < b.Position.Y {
+		return Vector{Y: -1}, true
+	}
+	return Vector{Y: 1}, true
+}
+
+// Check if the object's box is completely outside the world boundaries
+func _isBeyondBoundary(obj *Object, boundary Vector) bool {
+	return obj.positionRightX() < 0 || obj.positionLeftX() > boundary.X ||
+		obj.positionTopY() < 0 || obj.positionBottomY() > boundary.Y
+}
+
+// Get the contact keys sorted by the object IDs
+func _sortedContactKeys(contacts map[contactKey]Vector) []contactKey {
+	keys := make([]contactKey, 0, len(contacts))
+	for key := range contacts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].a != keys[j].a {
+			return keys[i].a < keys[j].a
+		}
+		return keys[i].b < keys[j].b
+	})
+	return keys
+}
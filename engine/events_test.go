@@ -0,0 +1,185 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// eventsOfType filters the events by type.
+func eventsOfType(events []engine.Event, eventType engine.EventType) []engine.Event {
+	var result []engine.Event
+	for _, event := range events {
+		if event.Type == eventType {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func TestLandedEventOnPlatform(t *testing.T) {
+	platform := &engine.Object{ID: 1, Type: engine.Terrain, Size: engine.Vector{X: 200, Y: 20}, Position: engine.Vector{X: 500, Y: 300}}
+	hero := &engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 500, Y: 400}, GravityFactor: 1}
+
+	world := newTestWorld(platform, hero)
+	world.Gravity = 10
+	eng := &engine.Engine{}
+	eng.SetEventQueueSize(64)
+	eng.SetWorld(world, 0)
+	eng.StepN(100, 0.05)
+
+	events := eng.DrainEvents()
+	landed := eventsOfType(events, engine.Landed)
+	if len(landed) != 1 {
+		t.Fatalf("Expected a single landed event, got %v", landed)
+	}
+	if landed[0].ObjectID != hero.ID || !landed[0].HasOther || landed[0].OtherID != platform.ID {
+		t.Errorf("Expected creature to land on the platform, got %+v", landed[0])
+	}
+	begin := eventsOfType(events, engine.ContactBegin)
+	if len(begin) != 1 || begin[0].ObjectID != platform.ID || begin[0].OtherID != hero.ID || begin[0].Normal.Y != -1 {
+		t.Errorf("Expected a single contact between the platform and the creature, got %v", begin)
+	}
+	if len(eng.DrainEvents()) != 0 {
+		t.Errorf("Expected the queue to be empty after draining")
+	}
+}
+
+func TestContactEndsWhenObjectsSeparate(t *testing.T) {
+	wall := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 20, Y: 200}, Position: engine.Vector{X: 200, Y: 500}}
+	hero := &engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 170, Y: 500}, Velocity: engine.Vector{X: 200}}
+
+	eng := &engine.Engine{}
+	var events []engine.Event
+	unsubscribe := eng.Subscribe(func(event engine.Event) {
+		events = append(events, event)
+	})
+	eng.SetWorld(newTestWorld(wall, hero), 0)
+	eng.Step(0.1)
+
+	if begin := eventsOfType(events, engine.ContactBegin); len(begin) != 1 || begin[0].Tick != 1 {
+		t.Fatalf("Expected contact to begin on the first tick, got %v", begin)
+	}
+
+	eng.SetVelocity(hero.ID, engine.Vector{X: -200})
+	eng.Step(0.1)
+	if end := eventsOfType(events, engine.ContactEnd); len(end) != 1 || end[0].Tick != 2 {
+		t.Fatalf("Expected contact to end on the second tick, got %v", end)
+	}
+
+	unsubscribe()
+	count := len(events)
+	eng.RemoveObject(hero.ID)
+	if len(events) != count {
+		t.Errorf("Expected no events after unsubscribing, got %v", events[count:])
+	}
+}
+
+func TestLifecycleAndBoundsEvents(t *testing.T) {
+	arrow := &engine.Object{ID: 1, Type: engine.Projectile, Size: engine.Vector{X: 10, Y: 2}, Position: engine.Vector{X: 990, Y: 500}, Velocity: engine.Vector{X: 1000}}
+
+	eng := &engine.Engine{}
+	eng.SetEventQueueSize(64)
+	eng.SetWorld(newTestWorld(), 0)
+	eng.UpsertObject(arrow)
+	eng.StepN(2, 0.1)
+	eng.RemoveObject(arrow.ID)
+
+	buf := make([]engine.Event, 2)
+	var events []engine.Event
+	for n := eng.PollEvents(buf); n > 0; n = eng.PollEvents(buf) {
+		events = append(events, buf[:n]...)
+	}
+
+	expected := []engine.EventType{engine.ObjectAdded, engine.LeftBounds, engine.ObjectRemoved}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %v", len(expected), events)
+	}
+	for i, event := range events {
+		if event.Type != expected[i] || event.ObjectID != arrow.ID {
+			t.Errorf("Expected event %d to be %d for the arrow, got %+v", i, expected[i], event)
+		}
+	}
+	if events[1].Tick != 1 {
+		t.Errorf("Expected arrow to leave the bounds on the first tick, got %d", events[1].Tick)
+	}
+}
+
+func TestEventQueueDropsOldest(t *testing.T) {
+	eng := &engine.Engine{}
+	eng.SetEventQueueSize(2)
+	eng.SetWorld(newTestWorld(), 0)
+	for id := 1; id <= 3; id++ {
+		eng.UpsertObject(&engine.Object{ID: id, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 500}})
+	}
+
+	events := eng.DrainEvents()
+	if len(events) != 2 || events[0].ObjectID != 2 || events[1].ObjectID != 3 {
+		t.Errorf("Expected only the last two events to be kept, got %v", events)
+	}
+}
+
+func TestEventQueueSize(t *testing.T) {
+	item := func(id int) *engine.Object {
+		return &engine.Object{ID: id, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 500}}
+	}
+
+	// The queue is disabled by default
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(), 0)
+	eng.UpsertObject(item(1))
+	if events := eng.DrainEvents(); len(events) != 0 {
+		t.Errorf("Expected the default queue to be disabled, got %v", events)
+	}
+
+	eng.SetEventQueueSize(1)
+	eng.UpsertObject(item(2))
+	if events := eng.DrainEvents(); len(events) != 1 || events[0].ObjectID != 2 {
+		t.Errorf("Expected the enabled queue to keep the event, got %v", events)
+	}
+
+	// Zero and negative capacities disable the queue
+	eng.SetEventQueueSize(1)
+	eng.UpsertObject(item(3))
+	eng.SetEventQueueSize(-1)
+	eng.UpsertObject(item(4))
+	if events := eng.DrainEvents(); len(events) != 0 {
+		t.Errorf("Expected the disabled queue to drop the events, got %v", events)
+	}
+}
+
+func TestEventQueueWrapsAround(t *testing.T) {
+	eng := &engine.Engine{}
+	eng.SetEventQueueSize(3)
+	eng.SetWorld(newTestWorld(), 0)
+	upsert := func(ids ...int) {
+		for _, id := range ids {
+			eng.UpsertObject(&engine.Object{ID: id, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 500}})
+		}
+	}
+
+	upsert(1, 2, 3)
+	buf := make([]engine.Event, 2)
+	if n := eng.PollEvents(buf); n != 2 || buf[0].ObjectID != 1 || buf[1].ObjectID != 2 {
+		t.Fatalf("Expected the two oldest events, got %v", buf[:n])
+	}
+
+	// The freed slots are reused, the oldest event is dropped once the ring is full again
+	upsert(4, 5, 6)
+	var ids []int
+	for n := eng.PollEvents(buf); n > 0; n = eng.PollEvents(buf) {
+		for _, event := range buf[:n] {
+			ids = append(ids, event.ObjectID)
+		}
+	}
+	if len(ids) != 3 || ids[0] != 4 || ids[1] != 5 || ids[2] != 6 {
+		t.Errorf("Expected the events of objects 4, 5 and 6, got %v", ids)
+	}
+
+	// Shrinking keeps the newest events
+	upsert(7, 8, 9)
+	eng.SetEventQueueSize(2)
+	if events := eng.DrainEvents(); len(events) != 2 || events[0].ObjectID != 8 || events[1].ObjectID != 9 {
+		t.Errorf("Expected the two newest events after shrinking, got %v", events)
+	}
+}
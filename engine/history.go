@@ -0,0 +1,144 @@
+package engine
+
+import "sort"
+
+// Position and size of an object at a past tick
+type historyState struct {
+	position Vector
+	size     Vector
+	obj      *Object // Recorded object, kept after it's removed from the world
+}
+
+// Moving objects of the world after a tick
+type historyFrame struct {
+	time    float64
+	objects map[int]historyState
+}
+
+// Keep the positions and sizes of the moving objects for the last ticks,
+// so the queries can be rewound to the time a client saw the world (lag compensation)
+// Zero or negative ticks disables the history
+func (engine *Engine) SetHistorySize(ticks int) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.historySize = max(ticks, 0)
+	engine.resetHistory()
+}
+
+// Get the simulated time of the current tick in seconds since the world was created
+func (engine *Engine) GetTime() float64 {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return engine.simulationTime
+}
+
+// Cast a ray against the world as it was at the time (see GetTime)
+// The moving objects are taken at their recorded positions and sizes, interpolated between the ticks:
+// objects created after the time are ignored, and objects removed since then can still be hit
+// The query runs on copies of the rewound objects, the world itself isn't changed
+// Times older than the history use the oldest recorded tick, newer ones use the current state
+func (engine *Engine) RewindRaycast(time float64, origin Vector, direction Vector, maxDistance float64, filter QueryFilter) (RaycastHit, bool) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	minCorner, maxCorner := _castBounds(origin, Vector{}, direction, maxDistance)
+	world := engine.rewind(time, minCorner, maxCorner)
+	return _cast(world, origin, Vector{}, direction, maxDistance, &filter)
+}
+
+// Find the objects overlapping the rectangle as it was at the time (see RewindRaycast)
+// Returns copies of the objects at their rewound positions sorted by ID
+func (engine *Engine) RewindQueryRect(time float64, minCorner Vector, maxCorner Vector, filter QueryFilter) []*Object {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	world := engine.rewind(time, minCorner, maxCorner)
+	return _cloneSortedByID(_queryRect(world, minCorner, maxCorner, &filter))
+}
+
+// -- Internal methods -- //
+
+// Record the moving objects after the tick into the ring buffer
+func (engine *Engine) recordHistory() {
+	if engine.historySize == 0 || engine.world == nil {
+		return
+	}
+	if len(engine.history) < engine.historySize {
+		engine.history = append(engine.history, historyFrame{})
+	} else {
+		engine.historyStart = (engine.historyStart + 1) % len(engine.history) // Overwrite the oldest frame
+	}
+	frame := &engine.history[(engine.historyStart+len(engine.history)-1)%len(engine.history)]
+	frame.time = engine.simulationTime
+	if frame.objects == nil {
+		frame.objects = make(map[int]historyState, len(engine.world.Objects))
+	} else {
+		clear(frame.objects)
+	}
+	for id, obj := range engine.world.Objects {
+		if !_isStatic(obj.Type) {
+			frame.objects[id] = historyState{position: obj.Position, size: obj.Size, obj: obj}
+		}
+	}
+}
+
+// Forget the recorded ticks
+func (engine *Engine) resetHistory() {
+	engine.history = engine.history[:0]
+	engine.historyStart = 0
+}
+
+// Get the recorded frame by its age order, 0 is the oldest
+func (engine *Engine) historyFrame(i int) *historyFrame {
+	return &engine.history[(engine.historyStart+i)%len(engine.history)]
+}
+
+// Get the world as it was at the time within the region between the corners
+// The result is a temporary world with the copies of the moving objects at their rewound states,
+// including the removed ones, and the current static objects, or the current world for the current time
+func (engine *Engine) rewind(time float64, minCorner Vector, maxCorner Vector) *World {
+	world := engine.world
+	count := len(engine.history)
+	if world == nil || count == 0 || time >= engine.historyFrame(count-1).time {
+		return world
+	}
+
+	// The newest frame not newer than the time, and the next one to interpolate to
+	i := sort.Search(count, func(i int) bool { return engine.historyFrame(i).time > time }) - 1
+	before, after, t := engine.historyFrame(max(i, 0)), (*historyFrame)(nil), 0.0
+	if i >= 0 {
+		after = engine.historyFrame(i + 1)
+		t = (time - before.time) / (after.time - before.time)
+	}
+
+	rewound := &World{Gravity: world.Gravity, Boundary: world.Boundary, Objects: make(map[int]*Object)}
+	for _, obj := range world.spatialIndex().query(minCorner.X, minCorner.Y, maxCorner.X, maxCorner.Y, nil) {
+		if _isStatic(obj.Type) {
+			rewound.Objects[obj.ID] = obj // Statics never move, read-only
+		}
+	}
+	for id, state := range before.objects {
+		if next, ok := after.objectState(id); ok {
+			state.position = Vector{
+				X: state.position.X + (next.position.X-state.position.X)*t,
+				Y: state.position.Y + (next.position.Y-state.position.Y)*t,
+			}
+		}
+		obj := *state.obj
+		obj.Position, obj.Size = state.position, state.size
+		if obj.positionRightX() < minCorner.X || obj.positionLeftX() > maxCorner.X ||
+			obj.positionTopY() < minCorner.Y || obj.positionBottomY() > maxCorner.Y {
+			continue // Outside of the queried region
+		}
+		obj.Impulses = nil // Not needed by the queries, the copy must not share them
+		rewound.Objects[id] = &obj
+	}
+	return rewound
+}
+
+// Get the state of the object in the frame, the frame can be nil
+func (frame *historyFrame) objectState(id int) (historyState, bool) {
+	if frame == nil {
+		return historyState{}, false
+	}
+	state, ok := frame.objects[id]
+	return state, ok
+}
@@ -0,0 +1,115 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// newRunnerEngine creates an engine with a creature running right at 100 units per second.
+func newRunnerEngine(historySize int) *engine.Engine {
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(
+		&engine.Object{ID: 1, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 100, Y: 20}, Velocity: engine.Vector{X: 100}},
+	), 0)
+	eng.SetHistorySize(historySize)
+	return eng
+}
+
+func TestRewindRaycast(t *testing.T) {
+	eng := newRunnerEngine(16)
+	eng.StepN(5, 0.1)
+	if now := eng.GetTime(); math.Abs(now-0.5) > 1e-9 {
+		t.Fatalf("Expected simulation time 0.5, got %f", now)
+	}
+
+	cases := []struct {
+		time     float64
+		distance float64
+	}{
+		{0.2, 110},  // Recorded tick, the creature was centered at 120
+		{0.25, 115}, // Between the ticks, interpolated
+		{0.0, 100},  // Older than the history, the oldest tick is used
+		{0.5, 140},  // Current state
+	}
+	for _, c := range cases {
+		hit, ok := eng.RewindRaycast(c.time, engine.Vector{X: 0, Y: 20}, engine.Vector{X: 1}, 1000, engine.QueryFilter{})
+		if !ok || hit.ObjectID != 1 || math.Abs(hit.Distance-c.distance) > 1e-9 {
+			t.Errorf("At %.2f expected hit at %.0f, got %v %v", c.time, c.distance, hit, ok)
+		}
+	}
+
+	if x := eng.GetObject(1).Position.X; math.Abs(x-150) > 1e-9 {
+		t.Errorf("Expected the creature to stay at 150, got %f", x)
+	}
+	if hit, ok := eng.Raycast(engine.Vector{X: 0, Y: 20}, engine.Vector{X: 1}, 1000, engine.QueryFilter{}); !ok || math.Abs(hit.Distance-140) > 1e-9 {
+		t.Errorf("Expected the spatial index to stay unchanged, got %v %v", hit, ok)
+	}
+}
+
+func TestRewindIgnoresObjectsCreatedLater(t *testing.T) {
+	eng := newRunnerEngine(16)
+	eng.StepN(3, 0.1)
+	eng.UpsertObject(&engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 300, Y: 20}})
+	eng.StepN(2, 0.1)
+
+	origin, right := engine.Vector{X: 200, Y: 20}, engine.Vector{X: 1}
+	if hit, ok := eng.RewindRaycast(0.5, origin, right, 1000, engine.QueryFilter{}); !ok || hit.ObjectID != 2 {
+		t.Errorf("Expected to hit the new creature now, got %v %v", hit, ok)
+	}
+	if hit, ok := eng.RewindRaycast(0.2, origin, right, 1000, engine.QueryFilter{}); ok {
+		t.Errorf("Expected no hit before the creature was created, got %v", hit)
+	}
+
+	found := eng.RewindQueryRect(0.1, engine.Vector{X: 100, Y: 0}, engine.Vector{X: 120, Y: 40}, engine.QueryFilter{})
+	if ids := objectIDs(found); len(ids) != 1 || ids[0] != 1 {
+		t.Fatalf("Expected the runner at its past position, got %v", ids)
+	}
+	if x := found[0].Position.X; math.Abs(x-110) > 1e-9 {
+		t.Errorf("Expected the copy at the rewound position 110, got %f", x)
+	}
+	if x := eng.GetObject(1).Position.X; math.Abs(x-150) > 1e-9 {
+		t.Errorf("Expected the runner to stay at 150, got %f", x)
+	}
+}
+
+func TestRewindKeepsRemovedObjects(t *testing.T) {
+	eng := newRunnerEngine(16)
+	eng.StepN(3, 0.1)
+	eng.RemoveObject(1)
+	eng.StepN(2, 0.1)
+
+	origin, right := engine.Vector{X: 0, Y: 20}, engine.Vector{X: 1}
+	if hit, ok := eng.RewindRaycast(0.5, origin, right, 1000, engine.QueryFilter{}); ok {
+		t.Errorf("Expected no hit after the creature was removed, got %v", hit)
+	}
+	if hit, ok := eng.RewindRaycast(0.2, origin, right, 1000, engine.QueryFilter{}); !ok || hit.ObjectID != 1 || math.Abs(hit.Distance-110) > 1e-9 {
+		t.Errorf("Expected to hit the removed creature at its past position, got %v %v", hit, ok)
+	}
+
+	found := eng.RewindQueryRect(0.2, engine.Vector{X: 100, Y: 0}, engine.Vector{X: 140, Y: 40}, engine.QueryFilter{})
+	if ids := objectIDs(found); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected the removed creature in the rewound rectangle, got %v", ids)
+	}
+	if obj := eng.GetObject(1); obj != nil {
+		t.Errorf("Expected the creature to stay removed, got %v", obj)
+	}
+}
+
+func TestRewindHistoryLimit(t *testing.T) {
+	eng := newRunnerEngine(2)
+	eng.StepN(5, 0.1)
+
+	// Only the last two ticks (0.4 and 0.5) are kept, older times use the oldest of them
+	hit, ok := eng.RewindRaycast(0.1, engine.Vector{X: 0, Y: 20}, engine.Vector{X: 1}, 1000, engine.QueryFilter{})
+	if !ok || math.Abs(hit.Distance-130) > 1e-9 {
+		t.Errorf("Expected hit at the oldest kept tick, got %v %v", hit, ok)
+	}
+
+	eng.SetHistorySize(0)
+	hit, ok = eng.RewindRaycast(0.1, engine.Vector{X: 0, Y: 20}, engine.Vector{X: 1}, 1000, engine.QueryFilter{})
+	if !ok || math.Abs(hit.Distance-140) > 1e-9 {
+		t.Errorf("Expected the current state without the history, got %v %v", hit, ok)
+	}
+}
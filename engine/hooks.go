@@ -0,0 +1,146 @@
+package engine
+
+// TickHook is game logic called at every tick inside the engine lock,
+// e.g. AI decisions, cooldowns and spawn timers
+// Elapsed is the duration of the tick in seconds
+//
+// The hook must change the world only through the TickWorld and must not call the Engine methods,
+// they would wait for the lock held by the tick
+type TickHook func(world *TickWorld, elapsed float64)
+
+// TickWorld gives the tick hooks access to the world inside the engine lock
+// It's valid only during the hook call, the calls after the hook returns do nothing
+type TickWorld struct {
+	engine *Engine
+	tick   uint64
+}
+
+// Registered tick hook
+type tickHook struct {
+	hook TickHook
+}
+
+// Add a hook called before every tick, before the objects are moved
+// Hooks are called in the order they were added at every tick of Step, StepN and Run,
+// including every fixed step, but not when the predicted inputs are replayed
+// Returns the function to remove the hook, don't call it from a hook
+func (engine *Engine) AddPreTickHook(hook TickHook) func() {
+	return engine.addHook(&engine.preTickHooks, hook)
+}
+
+// Add a hook called after every tick, after the objects are moved and the collisions are resolved
+// Changes made by the hook are reported by the events and recorded by the history of the same tick
+// Returns the function to remove the hook, don't call it from a hook
+func (engine *Engine) AddPostTickHook(hook TickHook) func() {
+	return engine.addHook(&engine.postTickHooks, hook)
+}
+
+// Get the number of the tick being simulated
+func (world *TickWorld) Tick() uint64 {
+	return world.tick
+}
+
+// Get the simulated time in seconds at the start of the tick for the pre-tick hooks
+// and at its end for the post-tick hooks
+func (world *TickWorld) Time() float64 {
+	if world.engine == nil {
+		return 0
+	}
+	return world.engine.simulationTime
+}
+
+// Get the object IDs sorted in ascending order, so the hooks visit the objects deterministically
+func (world *TickWorld) ObjectIDs() []int {
+	if world.engine == nil || world.engine.world == nil {
+		return nil
+	}
+	return sortedObjectIDs(world.engine.world.Objects)
+}
+
+// Get the object by id, nil if there is no such object
+// The object is the live one, its velocity, impulses and other properties can be changed directly,
+// use SetPosition, UpsertObject and RemoveObject for the position, size and type,
+// don't keep the object after the hook returns
+func (world *TickWorld) Object(id int) *Object {
+	if world.engine == nil {
+		return nil
+	}
+	return world.engine.getObject(id)
+}
+
+// Find the live objects whose boxes overlap the rectangle, sorted by ID
+func (world *TickWorld) QueryRect(minCorner Vector, maxCorner Vector, filter QueryFilter) []*Object {
+	if world.engine == nil {
+		return nil
+	}
+	objects := _queryRect(world.engine.getWorld(), minCorner, maxCorner, &filter)
+	sortObjectsByID(objects)
+	return objects
+}
+
+// Upsert an object to the world
+func (world *TickWorld) UpsertObject(obj *Object) {
+	if world.engine != nil {
+		world.engine.upsertObject(obj)
+	}
+}
+
+// Remove object by ID
+func (world *TickWorld) RemoveObject(id int) {
+	if world.engine != nil {
+		world.engine.removeObject(id)
+	}
+}
+
+// Add an impulse to an object
+func (world *TickWorld) AddImpulse(id int, direction Vector, damping float64) {
+	if world.engine != nil {
+		world.engine.addImpulse(id, direction, damping)
+	}
+}
+
+// Set the velocity of an object
+func (world *TickWorld) SetVelocity(id int, velocity Vector) {
+	if world.engine != nil {
+		world.engine.setVelocity(id, velocity)
+	}
+}
+
+// Set the position of an object
+func (world *TickWorld) SetPosition(id int, position Vector) {
+	if world.engine != nil {
+		world.engine.setPosition(id, position)
+	}
+}
+
+// -- Internal methods -- //
+
+// Register the hook in the list and return the function to remove it
+func (engine *Engine) addHook(hooks *[]*tickHook, hook TickHook) func() {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	registered := &tickHook{hook: hook}
+	*hooks = append(*hooks, registered)
+	return func() {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+		for i, other := range *hooks {
+			if other == registered {
+				*hooks = append((*hooks)[:i:i], (*hooks)[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Call the hooks with the world of the tick
+func (engine *Engine) runHooks(hooks []*tickHook, tick uint64, elapsed float64) {
+	if len(hooks) == 0 {
+		return
+	}
+	world := &TickWorld{engine: engine, tick: tick}
+	defer func() { world.engine = nil }() // Invalidate the handle kept by a hook
+	for _, registered := range hooks {
+		registered.hook(world, elapsed)
+	}
+}
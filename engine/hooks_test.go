@@ -0,0 +1,63 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestTickHooks(t *testing.T) {
+	eng := newCommandEngine()
+	eng.SetEventQueueSize(64)
+
+	var calls []string
+	var kept *engine.TickWorld
+	removePre := eng.AddPreTickHook(func(world *engine.TickWorld, elapsed float64) {
+		calls = append(calls, "pre")
+		if want := uint64(len(calls)+1) / 2; world.Tick() != want {
+			t.Errorf("Expected the pre-tick hook to get the tick being simulated %d, got %d", want, world.Tick())
+		}
+		world.SetVelocity(1, engine.Vector{X: 10 / elapsed}) // Moves by 10 in this tick
+	})
+	eng.AddPostTickHook(func(world *engine.TickWorld, elapsed float64) {
+		calls = append(calls, "post")
+		kept = world
+		if obj := world.Object(1); world.Tick() <= 2 && obj.Position.X != 110 {
+			t.Errorf("Expected the post-tick hook to see the moved object, got %v", obj.Position)
+		}
+		world.SetPosition(1, engine.Vector{X: 100, Y: 20})
+		if world.Tick() == 2 {
+			// Spawn timer
+			world.UpsertObject(&engine.Object{ID: 3, Type: engine.Item, Size: engine.Vector{X: 1, Y: 1}, Position: engine.Vector{X: 500, Y: 1}})
+		}
+	})
+
+	eng.Step(0.5)
+	eng.Step(0.5)
+	if len(calls) != 4 || calls[0] != "pre" || calls[1] != "post" {
+		t.Errorf("Expected pre and post hooks at every tick, got %v", calls)
+	}
+	if eng.GetObject(3) == nil {
+		t.Error("Expected the object spawned by the hook")
+	}
+	added := false
+	for _, event := range eng.DrainEvents() {
+		added = added || event.Type == engine.ObjectAdded && event.ObjectID == 3 && event.Tick == 2
+	}
+	if !added {
+		t.Error("Expected the spawn to be reported in the tick of the hook")
+	}
+
+	// The world handle is invalid after the hook returns
+	if kept.Object(1) != nil || kept.ObjectIDs() != nil {
+		t.Error("Expected the kept world to be invalid")
+	}
+	kept.RemoveObject(1)
+
+	removePre()
+	eng.SetVelocity(1, engine.Vector{})
+	eng.Step(0.5)
+	if len(calls) != 5 || eng.GetObject(1) == nil {
+		t.Errorf("Expected only the post-tick hook after the removal, got %v", calls)
+	}
+}
@@ -0,0 +1,196 @@
+package engine
+
+import "math"
+
+// Integrator is a numerical integration method for the object motion
+type Integrator int
+
+const (
+	// Analytic integrates gravity, impulses, drag and friction in the closed form (default)
+	// over the elapsed time, so trajectories don't depend on the tick duration
+	Analytic Integrator = iota
+
+	// SemiImplicitEuler is the semi-implicit (symplectic) Euler integrator
+	// Velocity is updated first, then the position with the new velocity
+	// Cheaper, but trajectories slightly depend on the tick duration
+	SemiImplicitEuler
+)
+
+// Set the numerical integration method
+func (engine *Engine) SetIntegrator(integrator Integrator) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.integrator = integrator
+}
+
+// -- Internal methods -- //
+
+// Integrate gravity, impulses and velocity of the object over the elapsed time
+// Drag decays both velocity components and friction decays the horizontal one as exp(-rate*t),
+// the decay is integrated together with the accelerations, so the trajectories don't depend on the tick duration
+// Updates the velocity and returns the displacement of the object
+func _integrate(obj *Object, gravity float64, elapsed float64, integrator Integrator, friction float64) Vector {
+	decay := Vector{X: math.Max(obj.Drag, 0) + math.Max(friction, 0), Y: math.Max(obj.Drag, 0)}
+	initial := obj.Velocity
+	velocity := Vector{X: initial.X * math.Exp(-decay.X*elapsed), Y: initial.Y * math.Exp(-decay.Y*elapsed)}
+	displacement := Vector{
+		X: initial.X * _decayIntegral(-decay.X, elapsed),
+		Y: initial.Y * _decayIntegral(-decay.Y, elapsed),
+	}
+	obj.Velocity = velocity
+	displacement = displacement.add(_applyGravity(obj, gravity, elapsed, integrator, decay))
+	displacement = displacement.add(_applyImpulses(obj, elapsed, integrator, decay))
+	if integrator == SemiImplicitEuler {
+		// Position is moved with the new velocity
+		displacement = Vector{X: obj.Velocity.X * elapsed, Y: obj.Velocity.Y * elapsed}
+	}
+	if friction > 0 && math.Abs(obj.Velocity.X) < negligibleFloat {
+		obj.Velocity.X = 0 // Stop sliding
+	}
+	return displacement
+}
+
+// Apply gravity to an object over the elapsed time
+// Returns the displacement caused by gravity
+func _applyGravity(obj *Object, gravity float64, elapsed float64, integrator Integrator, decay Vector) Vector {
+	if obj.GravityFactor == 0 {
+		return Vector{}
+	}
+	acceleration := -gravity * obj.GravityFactor
+	velocityIntegral, positionIntegral := _accelerationIntegrals(0, -decay.Y, elapsed, integrator)
+	obj.Velocity.Y += acceleration * velocityIntegral
+	return Vector{Y: acceleration * positionIntegral}
+}
+
+// Apply impulses to an object over the elapsed time
+// Impulse direction is an acceleration decaying as Damping^t over time
+// Returns the displacement caused by the impulses
+func _applyImpulses(obj *Object, elapsed float64, integrator Integrator, decay Vector) Vector {
+	const negligibleImpulse = negligibleFloat // Threshold for removing negligible impulses
+
+	var displacement Vector
+	var prev *Impulse
+	current := obj.Impulses
+
+	for current != nil {
+		// Velocity and position integrals of the impulse over the elapsed time
+		damping := current.Damping // Damping factor
+		var velocityIntegralX, positionIntegralX, velocityIntegralY, positionIntegralY float64
+		if damping <= negligibleImpulse {
+			velocityIntegralX, positionIntegralX = _immediateIntegrals(-decay.X, elapsed)
+			velocityIntegralY, positionIntegralY = _immediateIntegrals(-decay.Y, elapsed)
+		} else {
+			rate := _impulseRate(damping)
+			velocityIntegralX, positionIntegralX = _accelerationIntegrals(rate, -decay.X, elapsed, integrator)
+			velocityIntegralY, positionIntegralY = _accelerationIntegrals(rate, -decay.Y, elapsed, integrator)
+		}
+		obj.Velocity.X += current.Direction.X * velocityIntegralX
+		obj.Velocity.Y += current.Direction.Y * velocityIntegralY
+		displacement.X += current.Direction.X * positionIntegralX
+		displacement.Y += current.Direction.Y * positionIntegralY
+
+		// Apply damping to the impulse based on elapsed time
+		switch {
+		case damping == 1:
+			// No damping
+		case damping <= negligibleImpulse:
+			// Immediate damping to zero
+			current.Direction.X = 0
+			current.Direction.Y = 0
+		default:
+			// Apply damping to the impulse direction
+			decay := math.Pow(damping, elapsed)
+			current.Direction.X *= decay
+			current.Direction.Y *= decay
+		}
+
+		// Check if the impulse has decayed to negligible values
+		if math.Abs(current.Direction.X) < negligibleImpulse && math.Abs(current.Direction.Y) < negligibleImpulse {
+			// Remove impulse from the list
+			if prev == nil {
+				obj.Impulses = current.Next
+			} else {
+				prev.Next = current.Next
+			}
+			current = current.Next
+			continue
+		}
+
+		// Move to the next impulse
+		prev = current
+		current = current.Next
+	}
+
+	return displacement
+}
+
+// Growth rate of the impulse decaying as damping^t, ln(damping)
+func _impulseRate(damping float64) float64 {
+	if math.Abs(damping-1) < 1e-12 {
+		return 0 // A constant acceleration
+	}
+	return math.Log(damping)
+}
+
+// Integrals of the immediate impulse (damping close to zero) over the elapsed time
+// The impulse changes the velocity by its direction at once at the start of the tick,
+// regardless of the elapsed time, so a jump doesn't depend on the tick duration,
+// the velocity change then decays as exp(decayRate*t) like the rest of the velocity
+func _immediateIntegrals(decayRate float64, elapsed float64) (float64, float64) {
+	return math.Exp(decayRate * elapsed), _decayIntegral(decayRate, elapsed)
+}
+
+// Integrals of the acceleration growing as exp(rate*t) for the velocity decaying as exp(decayRate*t)
+// over the elapsed time, the solution of dv/dt = exp(rate*t) + decayRate*v with v(0) = 0
+// - velocity integral: v(elapsed) = (exp(rate*elapsed) - exp(decayRate*elapsed)) / (rate - decayRate)
+// - position integral: ∫ v(t) dt over [0, elapsed]
+// The semi-implicit Euler integrator applies the acceleration at once and the decay after it
+func _accelerationIntegrals(rate float64, decayRate float64, elapsed float64, integrator Integrator) (float64, float64) {
+	if integrator == SemiImplicitEuler {
+		velocityIntegral := elapsed * math.Exp(decayRate*elapsed)
+		return velocityIntegral, velocityIntegral * elapsed
+	}
+	// exp(a*t) - exp(b*t) = 2 * exp(m*t) * sinh(d*t) with the midpoint m and the half difference d
+	mid, half := (rate+decayRate)/2, (rate-decayRate)/2
+	velocityIntegral := math.Exp(mid*elapsed) * elapsed * _sinhc(half*elapsed)
+	positionIntegral := elapsed * elapsed * _expcDivided(rate*elapsed, decayRate*elapsed)
+	return velocityIntegral, positionIntegral
+}
+
+// Integral of exp(rate*t) over [0, elapsed], the displacement of the unit velocity decaying at the rate
+func _decayIntegral(rate float64, elapsed float64) float64 {
+	return elapsed * _expc(rate*elapsed)
+}
+
+// (exp(z) - 1) / z, 1 at zero
+func _expc(z float64) float64 {
+	if z == 0 {
+		return 1
+	}
+	return math.Expm1(z) / z
+}
+
+// Derivative of _expc, (exp(z) * (z - 1) + 1) / z^2
+func _expcDerivative(z float64) float64 {
+	if math.Abs(z) < 1e-2 {
+		return 1.0/2 + z/3 + z*z/8 + z*z*z/30
+	}
+	return (math.Exp(z)*(z-1) + 1) / (z * z)
+}
+
+// sinh(z) / z, 1 at zero
+func _sinhc(z float64) float64 {
+	if math.Abs(z) < 1e-4 {
+		return 1 + z*z/6
+	}
+	return math.Sinh(z) / z
+}
+
+// Divided difference (_expc(a) - _expc(b)) / (a - b)
+// Close arguments use the derivative at the midpoint to avoid the cancellation
+func _expcDivided(a float64, b float64) float64 {
+	if math.Abs(a-b) < 1e-5 {
+		return _expcDerivative((a + b) / 2)
+	}
+	return (_expc(a) - _expc(b)) / (a - b)
+}
@@ -0,0 +1,97 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// simulateTrajectory advances a falling effect with an immediate and a decaying impulse and the air drag
+// for the total duration using the given tick and returns its final state.
+func simulateTrajectory(integrator engine.Integrator, tickMS, totalMS int, drag float64) *engine.Object {
+	spark := &engine.Object{
+		ID:            1,
+		Type:          engine.Effect,
+		Size:          engine.Vector{X: 1, Y: 1},
+		Position:      engine.Vector{X: 100, Y: 900},
+		Velocity:      engine.Vector{X: 50, Y: 20},
+		GravityFactor: 1,
+		Impulses: &engine.Impulse{
+			Direction: engine.Vector{X: 300, Y: 400},
+			Damping:   0.5,
+			Next: &engine.Impulse{
+				Direction: engine.Vector{X: -20},
+				Damping:   1,
+				Next:      &engine.Impulse{Direction: engine.Vector{X: -30, Y: 60}, Damping: 0}, // A jump
+			},
+		},
+		Drag: drag,
+	}
+	world := newTestWorld(spark)
+	world.Gravity = 98
+
+	eng := &engine.Engine{}
+	eng.SetIntegrator(integrator)
+	eng.SetWorld(world, 0)
+	eng.StepN(totalMS/tickMS, float64(tickMS)/1000)
+	return spark
+}
+
+func TestTrajectoryIndependentOfTick(t *testing.T) {
+	const totalMS = 528 // Divisible by 8, 16 and 33
+	for _, drag := range []float64{0, 1} {
+		reference := simulateTrajectory(engine.Analytic, 8, totalMS, drag)
+		for _, tickMS := range []int{16, 33} {
+			got := simulateTrajectory(engine.Analytic, tickMS, totalMS, drag)
+			if math.Abs(got.Position.X-reference.Position.X) > 1e-6 || math.Abs(got.Position.Y-reference.Position.Y) > 1e-6 {
+				t.Errorf("Expected position %v at %d ms tick with drag %f, got %v", reference.Position, tickMS, drag, got.Position)
+			}
+			if math.Abs(got.Velocity.X-reference.Velocity.X) > 1e-6 || math.Abs(got.Velocity.Y-reference.Velocity.Y) > 1e-6 {
+				t.Errorf("Expected velocity %v at %d ms tick with drag %f, got %v", reference.Velocity, tickMS, drag, got.Velocity)
+			}
+		}
+	}
+}
+
+func TestSemiImplicitEulerConverges(t *testing.T) {
+	const totalMS = 528
+	exact := simulateTrajectory(engine.Analytic, 8, totalMS, 1)
+	got := simulateTrajectory(engine.SemiImplicitEuler, 8, totalMS, 1)
+	if math.Abs(got.Position.Y-exact.Position.Y) > 1 {
+		t.Errorf("Expected semi-implicit Euler to stay close to %f, got %f", exact.Position.Y, got.Position.Y)
+	}
+}
+
+func TestImmediateImpulseIndependentOfElapsedTime(t *testing.T) {
+	for _, integrator := range []engine.Integrator{engine.Analytic, engine.SemiImplicitEuler} {
+		for _, elapsed := range []float64{0.008, 0.016, 0.1} {
+			spark := &engine.Object{ID: 1, Type: engine.Effect, Size: engine.Vector{X: 1, Y: 1}, Position: engine.Vector{X: 100, Y: 500}}
+			eng := &engine.Engine{}
+			eng.SetIntegrator(integrator)
+			eng.SetWorld(newTestWorld(spark), 0)
+
+			// The immediate impulse changes the velocity once, in the tick it was added in
+			eng.AddImpulse(spark.ID, engine.Vector{X: 100}, 0)
+			eng.Step(elapsed)
+			eng.Step(elapsed)
+			if math.Abs(spark.Velocity.X-100) > 1e-9 || spark.Impulses != nil {
+				t.Errorf("Expected velocity 100 and no impulses with integrator %d at %f s, got %f and %v",
+					integrator, elapsed, spark.Velocity.X, spark.Impulses)
+			}
+		}
+	}
+}
+
+func TestGravityScalesWithElapsedTime(t *testing.T) {
+	stone := &engine.Object{ID: 1, Type: engine.Effect, Size: engine.Vector{X: 1, Y: 1}, Position: engine.Vector{X: 100, Y: 900}, GravityFactor: 1}
+	world := newTestWorld(stone)
+	world.Gravity = 10
+
+	// RTT extrapolation applies gravity over the whole round-trip time
+	eng := &engine.Engine{}
+	eng.SetWorld(world, 2)
+	if math.Abs(stone.Velocity.Y+20) > 1e-9 || math.Abs(stone.Position.Y-880) > 1e-9 {
+		t.Errorf("Expected velocity -20 and position 880 after 2 seconds, got %f and %f", stone.Velocity.Y, stone.Position.Y)
+	}
+}
@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"math"
+	"sort"
+)
+
+// Default number of server snapshots kept for the interpolation
+const defaultSnapshotBufferSize = 32
+
+// Default delay of the rendered remote objects behind the server time (seconds)
+const defaultInterpolationDelay = 0.1
+
+// Default limit of the extrapolation past the newest snapshot (seconds)
+const defaultMaxExtrapolation = 0.25
+
+// State of a remote object in a server snapshot
+type remoteState struct {
+	position Vector
+	velocity Vector
+}
+
+// Remote objects of a server snapshot at the server time
+type snapshot struct {
+	time    float64
+	objects map[int]remoteState
+}
+
+// Configure the snapshot interpolation of the remote objects
+// Remote objects are rendered delay seconds behind the server time by interpolating
+// between the bracketing snapshots, when the snapshots are late the objects
+// are extrapolated by their velocity for up to maxExtrapolation seconds
+// Zero delay renders the newest snapshots, zero maxExtrapolation disables the extrapolation
+// Negative values (and a non-positive buffer size) restore the defaults
+func (engine *Engine) SetInterpolation(delay float64, bufferSize int, maxExtrapolation float64) {
+	if delay < 0 || math.IsNaN(delay) {
+		delay = defaultInterpolationDelay
+	}
+	if bufferSize <= 0 {
+		bufferSize = defaultSnapshotBufferSize
+	}
+	if maxExtrapolation < 0 || math.IsNaN(maxExtrapolation) {
+		maxExtrapolation = defaultMaxExtrapolation
+	}
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.interpolationSet = true
+	engine.interpolationDelay = delay
+	engine.snapshotBufferSize = bufferSize
+	engine.maxExtrapolation = maxExtrapolation
+	if size := engine.getSnapshotBufferSize(); len(engine.snapshots) > size {
+		engine.snapshots = engine.snapshots[len(engine.snapshots)-size:]
+	}
+}
+
+// Add the server world received at the server time (seconds) to the snapshot buffer
+// Only the remote objects (not created by the client) are kept,
+// snapshots arriving out of order are sorted by time
+func (engine *Engine) PushSnapshot(world *World, serverTime float64) {
+	if world == nil {
+		return
+	}
+	snap := snapshot{time: serverTime, objects: make(map[int]remoteState, len(world.Objects))}
+	for id, obj := range world.Objects {
+		if !obj.Client {
+			snap.objects[id] = remoteState{position: obj.Position, velocity: obj.Velocity}
+		}
+	}
+
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	i := sort.Search(len(engine.snapshots), func(i int) bool { return engine.snapshots[i].time >= serverTime })
+	if i < len(engine.snapshots) && engine.snapshots[i].time == serverTime {
+		engine.snapshots[i] = snap // Same time, replace
+	} else {
+		engine.snapshots = append(engine.snapshots, snapshot{})
+		copy(engine.snapshots[i+1:], engine.snapshots[i:])
+		engine.snapshots[i] = snap
+	}
+	if size := engine.getSnapshotBufferSize(); len(engine.snapshots) > size {
+		engine.snapshots = engine.snapshots[len(engine.snapshots)-size:]
+	}
+}
+
+// Get the rendered position of a remote object at the render time on the server clock (seconds)
+// Returns false if the object is not in the snapshot buffer
+func (engine *Engine) GetRemotePosition(id int, renderTime float64) (Vector, bool) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return engine.remotePosition(id, renderTime-engine.getInterpolationDelay())
+}
+
+// Get the rendered positions of all remote objects at the render time on the server clock (seconds)
+func (engine *Engine) GetRemotePositions(renderTime float64) map[int]Vector {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	if len(engine.snapshots) == 0 {
+		return nil
+	}
+	target := renderTime - engine.getInterpolationDelay()
+	positions := make(map[int]Vector)
+	visited := make(map[int]bool)
+	for _, snap := range engine.snapshots {
+		for id := range snap.objects {
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			if position, ok := engine.remotePosition(id, target); ok {
+				positions[id] = position
+			}
+		}
+	}
+	return positions
+}
+
+// -- Internal methods -- //
+
+// Interpolate or extrapolate the position of the remote object at the target time
+func (engine *Engine) remotePosition(id int, target float64) (Vector, bool) {
+	snapshots := engine.snapshots
+
+	// Find the newest snapshot with the object not newer than the target,
+	// and the oldest one with the object newer than the target
+	var before, after *snapshot
+	removed := false
+	for i := range snapshots {
+		if _, ok := snapshots[i].objects[id]; !ok {
+			// Missing from a snapshot after the last one with the object, removed by the server
+			removed = removed || before != nil && snapshots[i].time <= target
+			continue
+		}
+		removed = false
+		if snapshots[i].time <= target {
+			before = &snapshots[i]
+		} else if after == nil {
+			after = &snapshots[i]
+		}
+	}
+
+	switch {
+	case removed:
+		return Vector{}, false
+	case before != nil && after != nil:
+		a, b := before.objects[id], after.objects[id]
+		t := (target - before.time) / (after.time - before.time)
+		return Vector{
+			X: a.position.X + (b.position.X-a.position.X)*t,
+			Y: a.position.Y + (b.position.Y-a.position.Y)*t,
+		}, true
+	case before != nil:
+		// Snapshots are late, extrapolate by the velocity for a limited time
+		state := before.objects[id]
+		ahead := min(target-before.time, engine.getMaxExtrapolation())
+		return Vector{
+			X: state.position.X + state.velocity.X*ahead,
+			Y: state.position.Y + state.velocity.Y*ahead,
+		}, true
+	case after != nil:
+		return after.objects[id].position, true // Older than the buffer, show the oldest known state
+	}
+	return Vector{}, false
+}
+
+// Get the interpolation delay or the default one
+func (engine *Engine) getInterpolationDelay() float64 {
+	if !engine.interpolationSet {
+		return defaultInterpolationDelay
+	}
+	return engine.interpolationDelay
+}
+
+// Get the snapshot buffer size or the default one
+func (engine *Engine) getSnapshotBufferSize() int {
+	if !engine.interpolationSet {
+		return defaultSnapshotBufferSize
+	}
+	return engine.snapshotBufferSize
+}
+
+// Get the extrapolation limit or the default one
+func (engine *Engine) getMaxExtrapolation() float64 {
+	if !engine.interpolationSet {
+		return defaultMaxExtrapolation
+	}
+	return engine.maxExtrapolation
+}
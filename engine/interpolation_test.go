@@ -0,0 +1,84 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// newRemoteSnapshot creates a server snapshot with a remote creature and a client object.
+func newRemoteSnapshot(x float64, velocityX float64) *engine.World {
+	return newTestWorld(
+		&engine.Object{ID: 1, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: x, Y: 500}, Velocity: engine.Vector{X: velocityX}},
+		&engine.Object{ID: -1, Type: engine.Effect, Client: true, Size: engine.Vector{X: 4, Y: 4}, Position: engine.Vector{X: x, Y: 500}},
+	)
+}
+
+func TestRemoteObjectsAreInterpolated(t *testing.T) {
+	eng := &engine.Engine{}
+	eng.SetInterpolation(0.1, 4, 0.05)
+
+	// The snapshots arrive out of order, the server changes direction at 1.1
+	eng.PushSnapshot(newRemoteSnapshot(100, 100), 1.0)
+	eng.PushSnapshot(newRemoteSnapshot(80, -200), 1.2)
+	eng.PushSnapshot(newRemoteSnapshot(110, -200), 1.1)
+
+	tests := []struct {
+		renderTime float64
+		expected   float64
+	}{
+		{1.05, 100}, // Older than the buffer, the oldest state
+		{1.15, 105}, // Between 1.0 and 1.1
+		{1.25, 95},  // Between 1.1 and 1.2, no overshoot after the direction change
+		{1.3, 80},   // Exactly the newest snapshot
+		{1.32, 76},  // Extrapolated by the velocity
+		{2.0, 70},   // Extrapolation is limited to 0.05 seconds
+	}
+	for _, test := range tests {
+		position, ok := eng.GetRemotePosition(1, test.renderTime)
+		if !ok || math.Abs(position.X-test.expected) > 1e-9 {
+			t.Errorf("Expected position %f at %f, got %f", test.expected, test.renderTime, position.X)
+		}
+	}
+
+	if _, ok := eng.GetRemotePosition(-1, 1.15); ok {
+		t.Errorf("Expected client objects not to be interpolated")
+	}
+}
+
+func TestRemovedRemoteObjectDisappears(t *testing.T) {
+	eng := &engine.Engine{}
+	eng.PushSnapshot(newRemoteSnapshot(100, 100), 1.0)
+	eng.PushSnapshot(newTestWorld(), 1.1)
+
+	if positions := eng.GetRemotePositions(1.15); len(positions) != 1 || math.Abs(positions[1].X-105) > 1e-9 {
+		t.Errorf("Expected remote object before the removal, got %v", positions)
+	}
+	if positions := eng.GetRemotePositions(1.25); len(positions) != 0 {
+		t.Errorf("Expected remote object to disappear after the removal, got %v", positions)
+	}
+}
+
+func TestZeroInterpolationDelay(t *testing.T) {
+	eng := &engine.Engine{}
+	eng.SetInterpolation(0, 0, 0)
+	eng.PushSnapshot(newRemoteSnapshot(100, 100), 1.0)
+	eng.PushSnapshot(newRemoteSnapshot(110, 100), 1.1)
+
+	// Zero delay renders the newest snapshot, zero extrapolation keeps the object there
+	for _, renderTime := range []float64{1.1, 1.5} {
+		if position, ok := eng.GetRemotePosition(1, renderTime); !ok || position.X != 110 {
+			t.Errorf("Expected the newest position 110 at %f, got %f", renderTime, position.X)
+		}
+	}
+
+	// Negative values restore the defaults: 0.1 seconds of delay and 0.25 of extrapolation
+	eng.SetInterpolation(-1, -1, -1)
+	if position, _ := eng.GetRemotePosition(1, 1.15); math.Abs(position.X-105) > 1e-9 {
+		t.Errorf("Expected the default delay, got %f", position.X)
+	}
+	if position, _ := eng.GetRemotePosition(1, 2); math.Abs(position.X-135) > 1e-9 {
+		t.Errorf("Expected the default extrapolation limit, got %f", position.X)
+	}
+}
@@ -0,0 +1,93 @@
+package engine
+
+import "math"
+
+// Objects bouncing slower than this come to rest, so they don't jitter on the floor
+const minBounceSpeed = 1.0
+
+// Combine the material values of two objects in contact, the larger one wins
+func _combineMaterial(a, b float64) float64 {
+	return math.Max(a, b)
+}
+
+// Velocity along the axis after hitting a surface with the restitution
+func _bounce(speed, restitution float64) float64 {
+	speed = -speed * clamp(restitution, 0, 1)
+	if math.Abs(speed) < minBounceSpeed {
+		return 0
+	}
+	return speed
+}
+
+// Friction slowing down the horizontal movement of an object standing on the floor
+// or, if onStatics is set, on the top of a terrain or structure, zero in the air
+// The contact is checked at the start of the tick, so the friction is integrated with the motion
+func _standingFriction(obj *Object, world *World, onStatics bool) float64 {
+	if obj.movingUpward() {
+		return 0 // Jumping off
+	}
+	if obj.onTheFloor() {
+		return obj.Friction
+	}
+	if !onStatics {
+		return 0
+	}
+	friction, standing := 0.0, false
+	candidates := world.spatialIndex().query(
+		obj.positionLeftX(), obj.positionBottomY()-contactTolerance,
+		obj.positionRightX(), obj.positionBottomY()+contactTolerance,
+		nil,
+	)
+	for _, other := range candidates {
+		if other == obj || !_isStatic(other.Type) || !_collides(obj.Type, other.Type) {
+			continue
+		}
+		if normal, ok := _touching(obj, other); ok && normal.Y > 0 {
+			friction, standing = math.Max(friction, other.Friction), true
+		}
+	}
+	if !standing {
+		return 0
+	}
+	return _combineMaterial(obj.Friction, friction)
+}
+
+// Keep the object within the world boundaries, bouncing off the walls, the ceiling and the floor
+// Returns true if the object stands on the floor
+func _keepInBoundary(obj *Object, boundary Vector) bool {
+	minX, maxX := obj.Size.X/2, boundary.X-obj.Size.X/2
+	if obj.Position.X < minX || obj.Position.X > maxX {
+		obj.Position.X = clamp(obj.Position.X, minX, maxX)
+		if obj.Position.X == minX && obj.movingLeftward() || obj.Position.X == maxX && obj.movingRightward() {
+			obj.Velocity.X = _bounce(obj.Velocity.X, obj.Restitution)
+		}
+	}
+
+	maxY := boundary.Y - obj.Size.Y/2
+	if obj.Position.Y > maxY {
+		obj.Position.Y = maxY
+		if obj.movingUpward() {
+			obj.Velocity.Y = _bounce(obj.Velocity.Y, obj.Restitution)
+		}
+	}
+
+	return _landOnFloor(obj)
+}
+
+// Stop or bounce the object when it hits the floor moving downward
+// Objects rising off the floor are only kept from sinking below it
+// Returns true if the object stands on the floor
+func _landOnFloor(obj *Object) bool {
+	if !obj.onTheFloor() {
+		return false
+	}
+	if obj.movingUpward() {
+		obj.Position.Y = math.Max(obj.Position.Y, obj.Size.Y/2)
+		return false
+	}
+	obj.Position.Y = obj.Size.Y / 2
+	if obj.movingDownward() {
+		obj.Velocity.Y = _bounce(obj.Velocity.Y, obj.Restitution)
+	}
+	return !obj.movingUpward()
+}
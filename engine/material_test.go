@@ -0,0 +1,104 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestRestitutionBouncesOffTheFloor(t *testing.T) {
+	ball := &engine.Object{ID: 1, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 6}, Velocity: engine.Vector{Y: -100}, Restitution: 0.5}
+	brick := &engine.Object{ID: 2, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 600, Y: 6}, Velocity: engine.Vector{Y: -100}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(ball, brick), 0)
+	eng.Step(0.1)
+
+	if ball.Position.Y != 5 || ball.Velocity.Y != 50 {
+		t.Errorf("Expected ball to bounce off the floor with half the speed, got position %f and velocity %f", ball.Position.Y, ball.Velocity.Y)
+	}
+	if brick.Position.Y != 5 || brick.Velocity.Y != 0 {
+		t.Errorf("Expected brick without restitution to stop dead, got position %f and velocity %f", brick.Position.Y, brick.Velocity.Y)
+	}
+}
+
+func TestRestitutionBouncesOffTheWall(t *testing.T) {
+	wall := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 20, Y: 200}, Position: engine.Vector{X: 200, Y: 500}, Restitution: 1}
+	hero := &engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 170, Y: 500}, Velocity: engine.Vector{X: 200}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(wall, hero), 0)
+	eng.Step(0.1)
+
+	if hero.Position.X != 180 || hero.Velocity.X != -200 {
+		t.Errorf("Expected creature to bounce off the bouncy wall, got position %f and velocity %f", hero.Position.X, hero.Velocity.X)
+	}
+}
+
+func TestFrictionStopsSlidingOnTheFloor(t *testing.T) {
+	crate := &engine.Object{ID: 1, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 100, Y: 5}, Velocity: engine.Vector{X: 100}, GravityFactor: 1, Friction: 2}
+	puck := &engine.Object{ID: 2, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 100, Y: 50}, Velocity: engine.Vector{X: 100}, GravityFactor: 1}
+
+	world := newTestWorld(crate, puck)
+	world.Gravity = 10
+	eng := &engine.Engine{}
+	eng.SetWorld(world, 0)
+	eng.Step(0.5)
+
+	if expected := 100 * math.Exp(-1); math.Abs(crate.Velocity.X-expected) > 1e-9 {
+		t.Errorf("Expected friction to slow the crate down to %f, got %f", expected, crate.Velocity.X)
+	}
+	if puck.Velocity.X != 100 {
+		t.Errorf("Expected friction not to apply in the air, got %f", puck.Velocity.X)
+	}
+
+	eng.StepN(100, 0.1)
+	if crate.Velocity.X != 0 {
+		t.Errorf("Expected crate to stop sliding, got %f", crate.Velocity.X)
+	}
+}
+
+func TestDragSlowsDownInTheAir(t *testing.T) {
+	arrow := &engine.Object{ID: 1, Type: engine.Projectile, Size: engine.Vector{X: 10, Y: 2}, Position: engine.Vector{X: 100, Y: 500}, Velocity: engine.Vector{X: 300}, Drag: 0.5}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(arrow), 0)
+	eng.StepN(4, 0.5)
+
+	if expected := 300 * math.Exp(-1); math.Abs(arrow.Velocity.X-expected) > 1e-9 {
+		t.Errorf("Expected drag to slow the arrow down to %f, got %f", expected, arrow.Velocity.X)
+	}
+}
+
+func TestFrictionIndependentOfTick(t *testing.T) {
+	slide := func(tickMS int) *engine.Object {
+		crate := &engine.Object{ID: 1, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 100, Y: 5}, Velocity: engine.Vector{X: 100}, GravityFactor: 1, Friction: 2, Drag: 0.5}
+		world := newTestWorld(crate)
+		world.Gravity = 10
+		eng := &engine.Engine{}
+		eng.SetWorld(world, 0)
+		eng.StepN(528/tickMS, float64(tickMS)/1000)
+		return crate
+	}
+
+	reference := slide(8)
+	for _, tickMS := range []int{16, 33} {
+		if got := slide(tickMS); math.Abs(got.Position.X-reference.Position.X) > 1e-6 || math.Abs(got.Velocity.X-reference.Velocity.X) > 1e-6 {
+			t.Errorf("Expected the crate at %f with velocity %f at %d ms tick, got %f and %f", reference.Position.X, reference.Velocity.X, tickMS, got.Position.X, got.Velocity.X)
+		}
+	}
+}
+
+func TestRisingOffTheFloor(t *testing.T) {
+	balloon := &engine.Object{ID: 1, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 100, Y: 5}, Velocity: engine.Vector{Y: 0.5}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(balloon), 0)
+	eng.StepN(4, 0.01)
+
+	// Slowly rising objects are not snapped back to the floor
+	if expected := 5 + 0.5*0.04; math.Abs(balloon.Position.Y-expected) > 1e-9 || balloon.Velocity.Y != 0.5 {
+		t.Errorf("Expected the object to rise to %f, got position %f and velocity %f", expected, balloon.Position.Y, balloon.Velocity.Y)
+	}
+}
@@ -110,6 +110,10 @@ type Vector struct {
 }
 
 // Impulse represents a single impulse affecting an object
+// Direction is an acceleration decaying as Damping^t (t in seconds)
+// Immediate impulses (Damping <= 0.01) add the whole Direction to the velocity once, whatever the tick duration
+// Breaking change: the engine used to add Direction * elapsed of an immediate impulse (see README.md)
+//
 // Влияние разных значений Damping:
 // >1.0	     | Увеличение импульса.	Используется редко, например, для ускорения ракет.
 // 1.0	     | Нет затухания, импульс постоянный.	Редко используется, например, для постоянного ускорения.
@@ -118,6 +122,9 @@ type Vector struct {
 // 0.5	     | Быстрое затухание.	Эффекты, исчезающие почти сразу, например, магические частицы.
 // 0.1-0.2	 | Очень быстрое затухание.	Используется для взрывов, ударов, отскоков.
 // 0.0	     | Немедленное затухание.	Импульс исчезает сразу после применения.
+//
+// Direction - это ускорение, затухающее как Damping^t (t в секундах).
+// При немедленном затухании Direction сразу добавляется к скорости, независимо от длительности тика.
 type Impulse struct {
 	Direction Vector   // Direction and magnitude of the impulse
 	Damping   float64  // Damping factor
@@ -129,10 +136,15 @@ type Impulse struct {
 // Difference between Object and Particle:
 // - Object is always in the world, and can be removed only by the server
 // - Particle can fly off the screen and be removed by the client
-// - Anchor position for objects usually is the bottom center of the object
-// - Anchor position for particles usually is the center of the particle
+// - Anchor of objects is usually the offset of the bottom center from the center, (0, -Size.Y/2)
+// - Anchor of particles is usually zero, the center of the particle
 // - Usually ids of objects are unique, positive integers assigned by the server
 // - Usually ids of particles are negative integers assigned by the client or server
+//
+// Position is the center of the object's box, so an object resting on the floor
+// has Position.Y == Size.Y/2 and an object on a static collider has its bottom at the collider's top
+// Breaking change: older engine versions clamped the floor to Position.Y == 0,
+// clients placing objects by their bottom edge have to add Size.Y/2 (or use Anchor to find the bottom)
 type Object struct {
 	ID            int        // ID represents the object ID
 	Type          ObjectType // Type of the object
@@ -143,6 +155,10 @@ type Object struct {
 	Anchor        Vector     // Anchor represents the anchor position for the object from the center of the object
 	GravityFactor float64    // Gravity factor (0 = no grav, 1 = full, 2 = double, -1 = reverse, etc.)
 	Impulses      *Impulse   // Linked list of active impulses
+	Bullet        bool       // Bullet enables continuous collision detection for fast objects (always on for projectiles)
+	Restitution   float64    // Bounciness (0 = stops dead, 1 = bounces back with the same speed)
+	Friction      float64    // Ground friction, horizontal velocity decay rate (1/s) while standing on something
+	Drag          float64    // Linear air drag, velocity decay rate (1/s)
 }
 
 // World represents the game world
@@ -158,23 +174,67 @@ type World struct {
 
 	// Objects is a map of major game objects (e.g. players, enemies, bullets)
 	// Objects are always in the world, and can be removed only by the server
-	// Anchor position for objects is the bottom center of the object
+	// Position of an object is the center of its box, Anchor is an offset from the center,
+	// usually the bottom center (0, -Size.Y/2), and an object resting on the floor has Position.Y == Size.Y/2
 	// Usually ids of objects are unique, positive integers assigned by the server
 	Objects map[int]*Object
+
+	// Broadphase spatial index over the objects, built when the engine takes the world or on first use
+	index *spatialHash
 }
 
 // -- Public methods -- //
 
-func WorldFromBytes(data []byte) *World {
-	return deserializeWorldFromBytes(data)
+// Decode the world serialized by ToBytes
+// The bytes are verified first, so truncated or malicious data returns ErrInvalidWorld instead of panicking,
+// data written by a newer engine is decoded unless its header requires a newer format (ErrIncompatibleFormat)
+func WorldFromBytes(data []byte) (*World, error) {
+	header, err := WorldHeaderFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := header.Compatible(); err != nil {
+		return nil, err
+	}
+	return deserializeWorldFromBytes(data), nil
 }
 
+// Serialize the world with a header without the tick and the time, see ToBytesAt
 func (world *World) ToBytes() []byte {
-	return serializeWorldToBytes(world)
+	return serializeWorldToBytes(world, _currentHeader(0, 0))
+}
+
+// Reconstruct the world from the baseline and the delta made by DeltaToBytes
+// The baseline is not changed, the new world doesn't share any objects with it
+// The bytes are verified first, malformed data and deltas changing objects missing from the baseline
+// (made from another baseline) return ErrInvalidWorld instead of panicking,
+// deltas requiring a newer format return ErrIncompatibleFormat
+func WorldFromDelta(baseline *World, data []byte) (*World, error) {
+	header, err := WorldDeltaHeaderFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := header.Compatible(); err != nil {
+		return nil, err
+	}
+	return deserializeWorldDelta(baseline, data)
+}
+
+// Encode the objects added, removed and changed since the baseline world
+// A nil baseline is the empty world, so the delta contains every object
+func (world *World) DeltaToBytes(baseline *World) []byte {
+	return serializeWorldDelta(baseline, world)
 }
 
 // -- Internal methods -- //
 
+// Copy the object with its impulses, the copy doesn't share any memory with the original
+func (obj *Object) clone() *Object {
+	copied := *obj
+	copied.Impulses = obj.Impulses.clone()
+	return &copied
+}
+
 func (vec *Vector) add(other Vector) Vector {
 	return Vector{
 		X: vec.X + other.X,
@@ -196,6 +256,10 @@ func (vec *Vector) addY(y float64) Vector {
 	}
 }
 
+func (vec *Vector) dot(other Vector) float64 {
+	return vec.X*other.X + vec.Y*other.Y
+}
+
 func (vec *Vector) magnitude() float64 {
 	return math.Sqrt(vec.X*vec.X + vec.Y*vec.Y)
 }
@@ -225,6 +289,11 @@ func (obj *Object) positionBottomY() float64 {
 	return obj.Position.Y - obj.Size.Y/2
 }
 
+// Object top position Y coordinate
+func (obj *Object) positionTopY() float64 {
+	return obj.Position.Y + obj.Size.Y/2
+}
+
 // Object left position X coordinate
 func (obj *Object) positionLeftX() float64 {
 	return obj.Position.X - obj.Size.X/2
@@ -235,9 +304,9 @@ func (obj *Object) positionRightX() float64 {
 	return obj.Position.X + obj.Size.X/2
 }
 
-// Object is on the floor (bottom of the object is touching the floor)
+// Object is on the floor (bottom of the object is touching or below the floor)
 func (obj *Object) onTheFloor() bool {
-	return math.Abs(obj.positionBottomY()) < negligibleFloat
+	return obj.positionBottomY() < negligibleFloat
 }
 
 // Object moving upward (velocity is positive in the upward direction)
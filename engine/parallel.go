@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Minimum number of objects per worker, smaller worlds are integrated serially
+// because handing the chunks to the workers would cost more than the integration itself
+const minObjectsPerWorker = 256
+
+// Pool of goroutines running the tasks of the parallel integration
+// The goroutines live until the pool is closed
+type workerPool struct {
+	tasks chan func()
+}
+
+// Set the number of worker goroutines integrating gravity, impulses and velocity of the objects
+// The objects are split by ID into contiguous chunks, one per worker,
+// every object is moved only by its own worker and only reads itself and the statics, which never move
+// Each worker also finds the new spatial hash cells of its objects, and the index is updated
+// from the chunks in ID order after all the workers finish (merge step),
+// so the result doesn't depend on the number of workers and matches the serial integration exactly
+// Collisions between the moving objects are still resolved serially after the merge
+// 0 or 1 integrates serially and stops the workers (default), a negative number uses one worker per CPU
+func (engine *Engine) SetWorkers(workers int) {
+	if workers < 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	if engine.pool != nil {
+		engine.pool.close()
+		engine.pool = nil
+	}
+	engine.workers = workers
+	engine.chunks = nil
+	engine.moved = nil
+	if workers > 1 {
+		engine.pool = newWorkerPool(workers)
+	}
+}
+
+// -- Internal methods -- //
+
+// Start the goroutines of the pool
+func newWorkerPool(workers int) *workerPool {
+	pool := &workerPool{tasks: make(chan func())}
+	for range workers {
+		go func() {
+			for task := range pool.tasks {
+				task()
+			}
+		}()
+	}
+	return pool
+}
+
+// Stop the goroutines of the pool after their current tasks
+func (pool *workerPool) close() {
+	close(pool.tasks)
+}
+
+// Number of workers to integrate the objects with, 1 for the serial integration
+func (engine *Engine) integrationWorkers(objects int) int {
+	if engine.pool == nil {
+		return 1
+	}
+	return max(1, min(engine.workers, objects/minObjectsPerWorker))
+}
+
+// Integrate the objects of the world on the workers and merge the results into the spatial index
+// A panic in a worker is raised again on the calling goroutine after all the workers finish
+func (engine *Engine) updateParallel(world *World, elapsed float64, integrator Integrator, workers int) {
+	objects := engine.chunks[:0]
+	for _, obj := range world.Objects {
+		objects = append(objects, obj)
+	}
+	sortObjectsByID(objects) // Same chunks for the same world
+	defer func() {
+		clear(objects) // Don't keep the removed objects alive until the next tick
+		engine.chunks = objects[:0]
+	}()
+
+	for len(engine.moved) < workers {
+		engine.moved = append(engine.moved, nil)
+	}
+	index := world.spatialIndex()
+	size := (len(objects) + workers - 1) / workers
+	panics := make([]any, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for worker := range workers {
+		chunk := objects[min(worker*size, len(objects)):min((worker+1)*size, len(objects))]
+		moved := engine.moved[worker][:0]
+		engine.pool.tasks <- func() {
+			defer wg.Done()
+			defer func() { panics[worker] = recover() }()
+			for _, obj := range chunk {
+				obj._update(world, elapsed, integrator)
+				// The index is only read here, the moves are applied by the merge
+				if next := (spatialEntry{obj: obj, cells: index.rangeOfObject(obj)}); index.entries[obj.ID] != next {
+					moved = append(moved, next)
+				}
+			}
+			engine.moved[worker] = moved
+		}
+	}
+	wg.Wait()
+
+	for _, p := range panics {
+		if p != nil {
+			panic(p)
+		}
+	}
+
+	// Merge: move the objects to their new cells in ID order
+	for worker, moved := range engine.moved[:workers] {
+		for _, entry := range moved {
+			index.place(entry)
+		}
+		clear(moved)
+		engine.moved[worker] = moved[:0]
+	}
+}
@@ -0,0 +1,67 @@
+package engine_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestParallelUpdateMatchesSerial(t *testing.T) {
+	serial := &engine.Engine{}
+	serial.SetWorld(newCrowdedWorld(2000, 1000), 0)
+	parallel := &engine.Engine{}
+	parallel.SetWorld(newCrowdedWorld(2000, 1000), 0)
+	parallel.SetWorkers(4)
+	defer parallel.SetWorkers(0)
+
+	for tick := range 30 {
+		serial.Step(0.016)
+		parallel.Step(0.016)
+		if !reflect.DeepEqual(serial.GetWorld(), parallel.GetWorld()) {
+			t.Fatalf("Expected the parallel world to match the serial one at tick %d", tick+1)
+		}
+	}
+
+	// The merged spatial index finds the same objects
+	minCorner, maxCorner := engine.Vector{X: 100, Y: 0}, engine.Vector{X: 600, Y: 300}
+	want := objectIDs(serial.QueryRect(minCorner, maxCorner, engine.QueryFilter{}))
+	if got := objectIDs(parallel.QueryRect(minCorner, maxCorner, engine.QueryFilter{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the parallel index to find %v, got %v", want, got)
+	}
+}
+
+func TestSetWorkersBackToSerial(t *testing.T) {
+	eng := &engine.Engine{}
+	eng.SetWorld(newCrowdedWorld(2000, 1000), 0)
+	eng.SetWorkers(-1)
+	eng.Step(0.016)
+	eng.SetWorkers(0)
+	eng.Step(0.016)
+	if tick := eng.GetTick(); tick != 2 {
+		t.Errorf("Expected 2 ticks, got %d", tick)
+	}
+}
+
+// BenchmarkUpdateWorkers compares a tick of a huge world integrated serially and on the workers.
+// The gain depends on the number of CPUs, run it with -cpu to compare.
+func BenchmarkUpdateWorkers(b *testing.B) {
+	for _, workers := range []int{0, 2, 4, 8} {
+		name := "serial"
+		if workers > 0 {
+			name = fmt.Sprintf("workers=%d", workers)
+		}
+		b.Run(name, func(b *testing.B) {
+			eng := &engine.Engine{}
+			eng.SetWorld(newCrowdedWorld(20000, 5000), 0)
+			eng.SetWorkers(workers)
+			defer eng.SetWorkers(0)
+
+			b.ResetTimer()
+			for range b.N {
+				eng.Step(0.016)
+			}
+		})
+	}
+}
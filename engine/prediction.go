@@ -0,0 +1,191 @@
+package engine
+
+// Maximum number of ticks kept for the replay,
+// older unacknowledged inputs are dropped when the server doesn't respond for too long
+const maxPredictionTicks = 1024
+
+type InputKind int
+
+const (
+	// InputImpulse adds an impulse to the object (Vector is the direction)
+	InputImpulse InputKind = iota
+
+	// InputVelocity sets the velocity of the object (Vector is the velocity)
+	InputVelocity
+)
+
+// Input is a local player input recorded for the client-side prediction
+// The input is sent to the server with its sequence number,
+// the server acknowledges the last processed sequence with each snapshot
+type Input struct {
+	Sequence uint32    // Sequence number of the input, starts from 1
+	Tick     uint64    // Tick number the input was applied after
+	ObjectID int       // Object controlled by the input
+	Kind     InputKind // Kind of the input
+	Vector   Vector    // Impulse direction or velocity
+	Damping  float64   // Impulse damping
+}
+
+// Add an impulse to an object and record it as a predicted input
+// Returns the sequence number to send to the server with the input
+func (engine *Engine) PredictImpulse(id int, direction Vector, damping float64) uint32 {
+	engine.lock()
+	defer engine.mutex.Unlock()
+	return engine.predict(Input{ObjectID: id, Kind: InputImpulse, Vector: direction, Damping: damping})
+}
+
+// Set the velocity of an object and record it as a predicted input
+// Returns the sequence number to send to the server with the input
+func (engine *Engine) PredictVelocity(id int, velocity Vector) uint32 {
+	engine.lock()
+	defer engine.mutex.Unlock()
+	return engine.predict(Input{ObjectID: id, Kind: InputVelocity, Vector: velocity})
+}
+
+// Get the inputs not acknowledged by the server yet, in the order they were applied
+func (engine *Engine) PendingInputs() []Input {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return append([]Input(nil), engine.inputs...)
+}
+
+// Accept the authoritative world from the server,
+// which has processed the local inputs up to the lastAck sequence number
+//
+// The snapshot is taken as the state right after the acknowledged input,
+// the objects controlled by the unacknowledged inputs are rewound to it
+// and replayed tick by tick with those inputs up to the current tick,
+// all other objects keep the authoritative state
+func (engine *Engine) Reconcile(world *World, lastAck uint32) {
+	defer engine.dispatchEvents()
+	engine.lock()
+	defer engine.mutex.Unlock()
+
+	// Drop the acknowledged inputs and find the tick to replay from
+	acked := 0
+	for acked < len(engine.inputs) && engine.inputs[acked].Sequence <= lastAck {
+		engine.ackTick = engine.inputs[acked].Tick
+		acked++
+	}
+	engine.inputs = append(engine.inputs[:0], engine.inputs[acked:]...)
+
+	if world != nil && len(engine.inputs) > 0 {
+		from := engine.ackTick
+		if from < engine.durationsFrom {
+			from = engine.inputs[0].Tick // Acknowledged before the recording, assume the snapshot is just before the inputs
+		}
+		engine.replay(world, from)
+	}
+
+	engine.emitReplaced(engine.world, world)
+	engine.setWorld(world)
+	engine.resetTimestep()
+	engine.resetHistory()
+	engine.trimDurations()
+}
+
+// -- Internal methods -- //
+
+// Apply the input to the world and record it
+func (engine *Engine) predict(input Input) uint32 {
+	engine.inputSequence++
+	input.Sequence = engine.inputSequence
+	input.Tick = engine.tick
+	if len(engine.inputs) == 0 && len(engine.durations) == 0 {
+		engine.durationsFrom = engine.tick // Start recording the ticks
+	}
+	engine.inputs = append(engine.inputs, input)
+	_applyInput(engine.world, input)
+	return input.Sequence
+}
+
+// Apply the input to its object, if it exists
+func _applyInput(world *World, input Input) {
+	if world == nil {
+		return
+	}
+	obj := world.Objects[input.ObjectID]
+	if obj == nil {
+		return
+	}
+	switch input.Kind {
+	case InputImpulse:
+		obj.Impulses = &Impulse{Direction: input.Vector, Damping: input.Damping, Next: obj.Impulses}
+	case InputVelocity:
+		obj.Velocity = input.Vector
+	}
+}
+
+// Forget the recorded inputs and ticks
+func (engine *Engine) resetPrediction() {
+	engine.inputs = nil
+	engine.ackTick = 0
+	engine.durations = nil
+	engine.durationsFrom = engine.tick
+}
+
+// Remember the duration of the tick while there are inputs to replay
+func (engine *Engine) recordDuration(dt float64) {
+	if len(engine.inputs) == 0 && len(engine.durations) == 0 {
+		return
+	}
+	engine.durations = append(engine.durations, dt)
+	if len(engine.durations) > maxPredictionTicks {
+		// Too long without an acknowledgement, forget the oldest ticks and their inputs
+		drop := len(engine.durations) - maxPredictionTicks
+		engine.durations = append(engine.durations[:0], engine.durations[drop:]...)
+		engine.durationsFrom += uint64(drop)
+		for len(engine.inputs) > 0 && engine.inputs[0].Tick < engine.durationsFrom {
+			engine.inputs = engine.inputs[1:]
+		}
+	}
+}
+
+// Forget the recorded ticks not needed for the replay anymore
+func (engine *Engine) trimDurations() {
+	if len(engine.inputs) == 0 {
+		engine.durations = engine.durations[:0]
+		engine.durationsFrom = engine.tick
+		return
+	}
+	keepFrom := min(engine.ackTick, engine.inputs[0].Tick)
+	if keepFrom > engine.durationsFrom {
+		drop := min(int(keepFrom-engine.durationsFrom), len(engine.durations))
+		engine.durations = append(engine.durations[:0], engine.durations[drop:]...)
+		engine.durationsFrom += uint64(drop)
+	}
+}
+
+// Replay the pending inputs on a copy of the authoritative world from the tick to the current one
+// and move the replayed controlled objects to the authoritative world
+func (engine *Engine) replay(world *World, from uint64) {
+	controlled := make(map[int]bool)
+	for _, input := range engine.inputs {
+		controlled[input.ObjectID] = true
+	}
+
+	scratch := world.Clone()
+
+	current := engine.world
+	engine.world = scratch
+	next := 0
+	for tick := from; ; tick++ {
+		for ; next < len(engine.inputs) && engine.inputs[next].Tick <= tick; next++ {
+			if engine.inputs[next].Tick == tick {
+				_applyInput(scratch, engine.inputs[next])
+			}
+		}
+		if tick >= engine.tick || tick < engine.durationsFrom {
+			break
+		}
+		engine.update(engine.durations[tick-engine.durationsFrom])
+	}
+	engine.world = current
+
+	for id := range controlled {
+		if obj, ok := scratch.Objects[id]; ok && world.Objects[id] != nil {
+			world.Objects[id] = obj
+		}
+	}
+	world.index = nil // Rebuilt with the replayed objects when the world is set
+}
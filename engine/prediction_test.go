@@ -0,0 +1,56 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// newHeroSnapshot creates a server snapshot with the hero and another creature.
+func newHeroSnapshot(heroX float64, heroVelocity engine.Vector) *engine.World {
+	return newTestWorld(
+		&engine.Object{ID: 1, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: heroX, Y: 500}, Velocity: heroVelocity},
+		&engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 800, Y: 500}, Velocity: engine.Vector{X: -50}},
+	)
+}
+
+func TestReconcileReplaysPendingInputs(t *testing.T) {
+	eng := &engine.Engine{}
+	eng.SetWorld(newHeroSnapshot(100, engine.Vector{}), 0)
+
+	first := eng.PredictVelocity(1, engine.Vector{X: 100})
+	eng.StepN(2, 0.1)
+	second := eng.PredictVelocity(1, engine.Vector{X: 200})
+	eng.StepN(2, 0.1)
+
+	if x := eng.GetObject(1).Position.X; x != 160 {
+		t.Fatalf("Expected predicted position 160, got %f", x)
+	}
+	if pending := eng.PendingInputs(); len(pending) != 2 || pending[0].Sequence != first || pending[1].Sequence != second {
+		t.Fatalf("Expected both inputs to be pending, got %v", pending)
+	}
+
+	// The server processed the first input only, the second one is replayed
+	eng.Reconcile(newHeroSnapshot(100, engine.Vector{X: 100}), first)
+	if x := eng.GetObject(1).Position.X; x != 160 {
+		t.Errorf("Expected hero not to snap after the reconciliation, got %f", x)
+	}
+	if x := eng.GetObject(2).Position.X; x != 800 {
+		t.Errorf("Expected other objects to keep the authoritative state, got %f", x)
+	}
+	if pending := eng.PendingInputs(); len(pending) != 1 || pending[0].Sequence != second {
+		t.Errorf("Expected only the second input to be pending, got %v", pending)
+	}
+
+	// The server corrected the hero position
+	eng.Reconcile(newHeroSnapshot(105, engine.Vector{X: 100}), first)
+	if x := eng.GetObject(1).Position.X; x != 165 {
+		t.Errorf("Expected the correction to be carried through the replay, got %f", x)
+	}
+
+	// Everything is acknowledged, the snapshot is taken as is
+	eng.Reconcile(newHeroSnapshot(170, engine.Vector{X: 200}), second)
+	if x := eng.GetObject(1).Position.X; x != 170 || len(eng.PendingInputs()) != 0 {
+		t.Errorf("Expected the authoritative state without pending inputs, got %f", x)
+	}
+}
@@ -0,0 +1,265 @@
+package engine
+
+import (
+	"math"
+	"sort"
+)
+
+// QueryFilter selects the objects reported by the world queries
+type QueryFilter struct {
+	Types     []ObjectType       // Object types to include, all types if empty
+	Predicate func(*Object) bool // Custom check, all objects if nil
+}
+
+// RaycastHit is the first object hit by a ray, segment or box cast
+type RaycastHit struct {
+	ObjectID int     // ID of the hit object
+	Point    Vector  // Hit point (box center at the moment of impact for box casts)
+	Normal   Vector  // Surface normal of the hit object at the hit point
+	Distance float64 // Distance travelled along the direction before the hit
+}
+
+// Cast a ray from the origin in the direction and find the first object it hits
+// The direction doesn't have to be normalized, maxDistance limits the ray length
+// The filter predicate is called under the engine read lock and must not call the engine methods
+func (engine *Engine) Raycast(origin Vector, direction Vector, maxDistance float64, filter QueryFilter) (RaycastHit, bool) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return _cast(engine.getWorld(), origin, Vector{}, direction, maxDistance, &filter)
+}
+
+// Cast a segment from one point to another and find the first object it hits
+func (engine *Engine) SegmentCast(from Vector, to Vector, filter QueryFilter) (RaycastHit, bool) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	delta := Vector{X: to.X - from.X, Y: to.Y - from.Y}
+	return _cast(engine.getWorld(), from, Vector{}, delta, delta.magnitude(), &filter)
+}
+
+// Sweep a box of the size centered at the origin in the direction
+// and find the first object it hits
+func (engine *Engine) BoxCast(origin Vector, size Vector, direction Vector, maxDistance float64, filter QueryFilter) (RaycastHit, bool) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return _cast(engine.getWorld(), origin, size, direction, maxDistance, &filter)
+}
+
+// Find the objects whose boxes overlap the rectangle between the min and max corners
+// Returns copies of the objects sorted by ID
+func (engine *Engine) QueryRect(minCorner Vector, maxCorner Vector, filter QueryFilter) []*Object {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return _cloneSortedByID(_queryRect(engine.getWorld(), minCorner, maxCorner, &filter))
+}
+
+// Find the objects whose boxes overlap the circle
+// Returns copies of the objects sorted by ID
+func (engine *Engine) QueryRadius(center Vector, radius float64, filter QueryFilter) []*Object {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	world := engine.getWorld()
+	if world == nil || !(radius >= 0) {
+		return nil
+	}
+	var result []*Object
+	candidates := world.spatialIndex().query(center.X-radius, center.Y-radius, center.X+radius, center.Y+radius, nil)
+	for _, obj := range candidates {
+		if _distanceToBox(center, obj) <= radius && filter.matches(obj) {
+			result = append(result, obj)
+		}
+	}
+	return _cloneSortedByID(result)
+}
+
+// Find up to k objects closest to the point, measured to the nearest point of their boxes
+// Objects at the same distance are picked by the lower ID
+// Returns copies of the objects sorted by ID
+func (engine *Engine) QueryNearest(center Vector, k int, filter QueryFilter) []*Object {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	world := engine.getWorld()
+	if world == nil || k <= 0 || len(world.Objects) == 0 {
+		return nil
+	}
+
+	// Grow the search radius until k objects are found within it
+	// or the search box covers every object of the index
+	index := world.spatialIndex()
+	var candidates, found []*Object
+	for radius := index.cellSize; ; radius *= 2 {
+		candidates = index.query(center.X-radius, center.Y-radius, center.X+radius, center.Y+radius, candidates)
+		found = found[:0]
+		for _, obj := range candidates {
+			if _distanceToBox(center, obj) <= radius && filter.matches(obj) {
+				found = append(found, obj)
+			}
+		}
+		if len(found) >= k || len(candidates) == len(index.entries) || math.IsInf(radius, 1) {
+			if len(candidates) == len(index.entries) {
+				found = found[:0]
+				for _, obj := range candidates {
+					if filter.matches(obj) {
+						found = append(found, obj)
+					}
+				}
+			}
+			break
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		di, dj := _distanceToBox(center, found[i]), _distanceToBox(center, found[j])
+		if di != dj {
+			return di < dj
+		}
+		return found[i].ID < found[j].ID
+	})
+	if len(found) > k {
+		found = found[:k]
+	}
+	return _cloneSortedByID(found)
+}
+
+// -- Internal methods -- //
+
+// Check if the object passes the filter
+func (filter *QueryFilter) matches(obj *Object) bool {
+	if len(filter.Types) > 0 {
+		found := false
+		for _, t := range filter.Types {
+			if obj.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return filter.Predicate == nil || filter.Predicate(obj)
+}
+
+// Find the objects overlapping the rectangle, in no particular order
+func _queryRect(world *World, minCorner Vector, maxCorner Vector, filter *QueryFilter) []*Object {
+	if world == nil {
+		return nil
+	}
+	var result []*Object
+	for _, obj := range world.spatialIndex().query(minCorner.X, minCorner.Y, maxCorner.X, maxCorner.Y, nil) {
+		if obj.positionRightX() >= minCorner.X && obj.positionLeftX() <= maxCorner.X &&
+			obj.positionTopY() >= minCorner.Y && obj.positionBottomY() <= maxCorner.Y && filter.matches(obj) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// Sweep a box (a ray for zero size) and find the first hit
+// Hits at the same distance are resolved by the lower object ID
+func _cast(world *World, origin Vector, size Vector, direction Vector, maxDistance float64, filter *QueryFilter) (RaycastHit, bool) {
+	length := direction.magnitude()
+	if world == nil || length < negligibleFloat || !(maxDistance >= 0) || math.IsNaN(length) {
+		return RaycastHit{}, false
+	}
+	dir := Vector{X: direction.X / length, Y: direction.Y / length}
+	half := Vector{X: math.Abs(size.X) / 2, Y: math.Abs(size.Y) / 2}
+
+	var candidates []*Object
+	if math.IsInf(maxDistance, 1) {
+		for _, obj := range world.Objects {
+			candidates = append(candidates, obj) // Endless ray, check every object
+		}
+	} else {
+		minCorner, maxCorner := _castBounds(origin, size, direction, maxDistance)
+		candidates = world.spatialIndex().query(minCorner.X, minCorner.Y, maxCorner.X, maxCorner.Y, nil)
+	}
+
+	var best RaycastHit
+	found := false
+	for _, obj := range candidates {
+		if !filter.matches(obj) {
+			continue
+		}
+		// Sweeping a box is a ray cast against the box expanded by its half size
+		distance, normal, ok := _rayBox(origin, dir, maxDistance,
+			obj.positionLeftX()-half.X, obj.positionBottomY()-half.Y,
+			obj.positionRightX()+half.X, obj.positionTopY()+half.Y,
+		)
+		if !ok || found && (distance > best.Distance || distance == best.Distance && obj.ID > best.ObjectID) {
+			continue
+		}
+		best = RaycastHit{
+			ObjectID: obj.ID,
+			Point:    Vector{X: origin.X + dir.X*distance, Y: origin.Y + dir.Y*distance},
+			Normal:   normal,
+			Distance: distance,
+		}
+		found = true
+	}
+	return best, found
+}
+
+// Get the box covered by the cast, infinite for an endless or invalid one
+func _castBounds(origin Vector, size Vector, direction Vector, maxDistance float64) (Vector, Vector) {
+	length := direction.magnitude()
+	if math.IsInf(maxDistance, 1) || length < negligibleFloat || math.IsNaN(length) {
+		return Vector{X: math.Inf(-1), Y: math.Inf(-1)}, Vector{X: math.Inf(1), Y: math.Inf(1)}
+	}
+	half := Vector{X: math.Abs(size.X) / 2, Y: math.Abs(size.Y) / 2}
+	end := Vector{X: origin.X + direction.X/length*maxDistance, Y: origin.Y + direction.Y/length*maxDistance}
+	return Vector{X: math.Min(origin.X, end.X) - half.X, Y: math.Min(origin.Y, end.Y) - half.Y},
+		Vector{X: math.Max(origin.X, end.X) + half.X, Y: math.Max(origin.Y, end.Y) + half.Y}
+}
+
+// Intersect a ray with a normalized direction and a box (slab method)
+// Returns the distance to the entry point and the normal of the entered side,
+// a ray starting inside the box hits it at zero distance with the normal against the direction
+func _rayBox(origin Vector, dir Vector, maxDistance float64, minX, minY, maxX, maxY float64) (float64, Vector, bool) {
+	near, far := math.Inf(-1), math.Inf(1)
+	var normal Vector
+
+	// Check a single axis slab, narrowing the [near, far] interval
+	slab := func(o, d, lo, hi float64, axis Vector) bool {
+		if d == 0 {
+			return o > lo && o < hi // Parallel to the slab, grazing the side is a miss
+		}
+		t1, t2 := (lo-o)/d, (hi-o)/d
+		n := Vector{X: -axis.X, Y: -axis.Y} // Entering through the lower side
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			n = axis // Entering through the upper side
+		}
+		if t1 > near {
+			near, normal = t1, n
+		}
+		far = math.Min(far, t2)
+		return true
+	}
+
+	if !slab(origin.X, dir.X, minX, maxX, Vector{X: 1}) || !slab(origin.Y, dir.Y, minY, maxY, Vector{Y: 1}) {
+		return 0, Vector{}, false
+	}
+	if near > far || far <= 0 || near > maxDistance {
+		return 0, Vector{}, false
+	}
+	if near < 0 {
+		return 0, Vector{X: -dir.X, Y: -dir.Y}, true // Started inside the box
+	}
+	return near, normal, true
+}
+
+// Distance from the point to the nearest point of the object's box, zero inside the box
+func _distanceToBox(point Vector, obj *Object) float64 {
+	dx := math.Max(math.Max(obj.positionLeftX()-point.X, point.X-obj.positionRightX()), 0)
+	dy := math.Max(math.Max(obj.positionBottomY()-point.Y, point.Y-obj.positionTopY()), 0)
+	return math.Hypot(dx, dy)
+}
+
+// Copy the objects and sort the copies by ID
+func _cloneSortedByID(objects []*Object) []*Object {
+	sortObjectsByID(objects)
+	for i, obj := range objects {
+		objects[i] = obj.clone()
+	}
+	return objects
+}
@@ -0,0 +1,102 @@
+package engine_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestRaycastHitsFirstObject(t *testing.T) {
+	near := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 20, Y: 200}, Position: engine.Vector{X: 300, Y: 500}}
+	far := &engine.Object{ID: 2, Type: engine.Structure, Size: engine.Vector{X: 20, Y: 200}, Position: engine.Vector{X: 600, Y: 500}}
+	hero := &engine.Object{ID: 3, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 100, Y: 500}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(far, near, hero), 0)
+
+	notHero := engine.QueryFilter{Predicate: func(obj *engine.Object) bool { return obj.ID != hero.ID }}
+	hit, ok := eng.Raycast(hero.Position, engine.Vector{X: 2}, 1000, notHero)
+	if !ok || hit.ObjectID != near.ID {
+		t.Fatalf("Expected ray to hit the near structure, got %+v", hit)
+	}
+	if hit.Distance != 190 || hit.Point != (engine.Vector{X: 290, Y: 500}) || hit.Normal != (engine.Vector{X: -1}) {
+		t.Errorf("Expected hit at 290 with the normal facing left, got %+v", hit)
+	}
+
+	if _, ok := eng.Raycast(hero.Position, engine.Vector{X: 1}, 150, notHero); ok {
+		t.Errorf("Expected ray shorter than the distance to miss")
+	}
+
+	hit, ok = eng.Raycast(hero.Position, engine.Vector{X: 1}, 1000, engine.QueryFilter{Types: []engine.ObjectType{engine.Creature}})
+	if !ok || hit.ObjectID != hero.ID || hit.Distance != 0 {
+		t.Errorf("Expected ray starting inside the creature to hit it at zero distance, got %+v", hit)
+	}
+}
+
+func TestSegmentCastAndBoxCast(t *testing.T) {
+	ground := &engine.Object{ID: 1, Type: engine.Terrain, Size: engine.Vector{X: 1000, Y: 20}, Position: engine.Vector{X: 500, Y: 10}}
+	crate := &engine.Object{ID: 2, Type: engine.Item, Size: engine.Vector{X: 20, Y: 20}, Position: engine.Vector{X: 520, Y: 30}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(ground, crate), 0)
+
+	hit, ok := eng.SegmentCast(engine.Vector{X: 500, Y: 100}, engine.Vector{X: 500, Y: 0}, engine.QueryFilter{})
+	if !ok || hit.ObjectID != ground.ID || hit.Point.Y != 20 || hit.Normal != (engine.Vector{Y: 1}) {
+		t.Errorf("Expected segment to hit the ground surface, got %+v", hit)
+	}
+	if _, ok := eng.SegmentCast(engine.Vector{X: 500, Y: 100}, engine.Vector{X: 500, Y: 50}, engine.QueryFilter{}); ok {
+		t.Errorf("Expected segment above the ground to miss")
+	}
+
+	// The box is wide enough to land on the crate next to the ray
+	hit, ok = eng.BoxCast(engine.Vector{X: 500, Y: 100}, engine.Vector{X: 24, Y: 10}, engine.Vector{Y: -1}, 200, engine.QueryFilter{})
+	if !ok || hit.ObjectID != crate.ID || hit.Point.Y != 45 || hit.Distance != 55 {
+		t.Errorf("Expected box to stop on top of the crate, got %+v", hit)
+	}
+}
+
+// objectIDs collects the IDs of the objects in order.
+func objectIDs(objects []*engine.Object) []int {
+	ids := make([]int, len(objects))
+	for i, obj := range objects {
+		ids[i] = obj.ID
+	}
+	return ids
+}
+
+func TestAreaQueries(t *testing.T) {
+	world := newTestWorld()
+	for id := 1; id <= 10; id++ {
+		world.Objects[id] = &engine.Object{ID: id, Type: engine.Creature, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: float64(1000 - id*100), Y: 500}}
+	}
+	world.Objects[11] = &engine.Object{ID: 11, Type: engine.Item, Size: engine.Vector{X: 10, Y: 10}, Position: engine.Vector{X: 500, Y: 520}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(world, 0)
+
+	rect := eng.QueryRect(engine.Vector{X: 290, Y: 400}, engine.Vector{X: 510, Y: 600}, engine.QueryFilter{})
+	if got := fmt.Sprint(objectIDs(rect)); got != "[5 6 7 11]" {
+		t.Errorf("Expected objects 5, 6, 7 and 11 in the rectangle, got %s", got)
+	}
+
+	creatures := engine.QueryFilter{Types: []engine.ObjectType{engine.Creature}}
+	circle := eng.QueryRadius(engine.Vector{X: 500, Y: 500}, 100, creatures)
+	if got := fmt.Sprint(objectIDs(circle)); got != "[4 5 6]" {
+		t.Errorf("Expected creatures 4, 5 and 6 in the circle, got %s", got)
+	}
+
+	nearest := eng.QueryNearest(engine.Vector{X: 880, Y: 500}, 3, engine.QueryFilter{})
+	if got := fmt.Sprint(objectIDs(nearest)); got != "[1 2 3]" {
+		t.Errorf("Expected objects 1, 2 and 3 to be the nearest, got %s", got)
+	}
+	if all := eng.QueryNearest(engine.Vector{}, 100, creatures); len(all) != 10 {
+		t.Errorf("Expected all 10 creatures when k exceeds the count, got %d", len(all))
+	}
+
+	// Results are copies, changing them doesn't affect the world
+	rect[0].Position.X = 0
+	if world.Objects[5].Position.X != 500 {
+		t.Errorf("Expected query results to be copies of the objects")
+	}
+}
@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidTick is returned by RunContext for a non-positive or non-finite tickMS
+	ErrInvalidTick = errors.New("engine: invalid tickMS")
+
+	// ErrNoWorld is returned by RunContext when there is no world to update
+	ErrNoWorld = errors.New("engine: no world")
+
+	// ErrRunning is returned by RunContext when the update loop is already running
+	ErrRunning = errors.New("engine: already running")
+
+	// ErrUpdatePanic is reported by Err when the update or an event handler panicked
+	ErrUpdatePanic = errors.New("engine: update panicked")
+)
+
+// Update loop started by Run, one per call
+type runLoop struct {
+	stop   chan struct{} // Closed to stop both goroutines
+	done   chan struct{} // Closed when both goroutines have exited
+	signal chan struct{} // Update signal from the ticker goroutine
+	once   sync.Once     // Guards stop and err
+	err    error         // Reason the loop stopped, read only after done is closed
+}
+
+// Start the world update loop in the background, updating the world every tickMS milliseconds
+// The loop runs until Stop is called, the context is canceled or the update panics,
+// use Done to wait for it and Err to get the reason it stopped
+// Returns ErrInvalidTick, ErrNoWorld or ErrRunning if the loop can't be started
+func (engine *Engine) RunContext(ctx context.Context, tickMS float64) error {
+	if tickMS <= 0 || math.IsInf(tickMS, 0) || math.IsNaN(tickMS) {
+		return fmt.Errorf("%w: %v", ErrInvalidTick, tickMS)
+	}
+
+	engine.mutex.Lock()
+	// Wait for the previous loop which stopped by itself to exit, so the loops never overlap
+	for engine.loop != nil && !engine.loop.finished() {
+		if !engine.loop.stopped() {
+			engine.mutex.Unlock()
+			return ErrRunning
+		}
+		done := engine.loop.done
+		engine.mutex.Unlock()
+		<-done
+		engine.mutex.Lock()
+	}
+	if engine.world == nil {
+		engine.mutex.Unlock()
+		return ErrNoWorld
+	}
+	loop := &runLoop{
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		signal: make(chan struct{}, 1), // Обеспечиваем буфер
+	}
+	engine.loop = loop
+	engine.lastUpdate = time.Now()
+	engine.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		loop.tick(ctx, time.Duration(tickMS*float64(time.Millisecond)))
+	}()
+	go func() {
+		defer wg.Done()
+		engine.updateLoop(loop)
+	}()
+	go func() {
+		wg.Wait()
+		close(loop.done)
+	}()
+	return nil
+}
+
+// Get a channel closed when the update loop has stopped and both of its goroutines have exited
+// The channel is already closed if the loop was never started
+func (engine *Engine) Done() <-chan struct{} {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	if engine.loop == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return engine.loop.done
+}
+
+// Get the reason the last update loop stopped once Done is closed:
+// nil after Stop, the context error after its cancellation, ErrUpdatePanic with the stack trace after a panic
+// Returns nil while the loop is running
+func (engine *Engine) Err() error {
+	engine.mutex.RLock()
+	loop := engine.loop
+	engine.mutex.RUnlock()
+	if loop == nil || !loop.finished() {
+		return nil
+	}
+	return loop.err
+}
+
+// -- Internal methods -- //
+
+// Signal the update goroutine every interval until stopped or the context is canceled
+func (loop *runLoop) tick(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop() // Остановка таймера
+	for {
+		select {
+		case <-ticker.C:
+			select {
+			case loop.signal <- struct{}{}:
+			default: // Skip if already updating
+			}
+		case <-ctx.Done():
+			loop.cancel(ctx.Err())
+			return
+		case <-loop.stop:
+			return // Завершаем выполнение
+		}
+	}
+}
+
+// Update the world on every signal until stopped, stop the loop if the update panics
+func (engine *Engine) updateLoop(loop *runLoop) {
+	for {
+		select {
+		case <-loop.signal:
+			if err := engine.updateOnce(); err != nil {
+				loop.cancel(err)
+				return
+			}
+		case <-loop.stop:
+			return // Завершаем выполнение
+		}
+	}
+}
+
+// Advance the world by the time elapsed since the last update and dispatch the events
+// A panic in the update or in the event handlers is recovered and returned as ErrUpdatePanic
+func (engine *Engine) updateOnce() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v\n%s", ErrUpdatePanic, r, debug.Stack())
+		}
+	}()
+	defer engine.dispatchEvents()
+	engine.lock()
+	defer engine.mutex.Unlock()
+	now := time.Now()                               // Current time
+	elapsed := now.Sub(engine.lastUpdate).Seconds() // Elapsed time since last update
+	engine.lastUpdate = now                         // Set last update time
+	engine.stepElapsed(elapsed)                     // Update the world
+	return nil
+}
+
+// Stop both goroutines, only the first reason is kept
+func (loop *runLoop) cancel(err error) {
+	loop.once.Do(func() {
+		loop.err = err
+		close(loop.stop) // Сигнал для завершения всех горутин
+	})
+}
+
+// Check if the loop was asked to stop
+func (loop *runLoop) stopped() bool {
+	select {
+	case <-loop.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Check if both goroutines of the loop have exited
+func (loop *runLoop) finished() bool {
+	select {
+	case <-loop.done:
+		return true
+	default:
+		return false
+	}
+}
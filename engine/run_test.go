@@ -0,0 +1,89 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestRunContextErrors(t *testing.T) {
+	eng := &engine.Engine{}
+	if err := eng.RunContext(context.Background(), 10); !errors.Is(err, engine.ErrNoWorld) {
+		t.Errorf("Expected ErrNoWorld, got %v", err)
+	}
+	eng = newCommandEngine()
+	if err := eng.RunContext(context.Background(), 0); !errors.Is(err, engine.ErrInvalidTick) {
+		t.Errorf("Expected ErrInvalidTick, got %v", err)
+	}
+	select {
+	case <-eng.Done():
+	default:
+		t.Error("Expected Done to be closed before the first run")
+	}
+
+	if err := eng.RunContext(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.RunContext(context.Background(), 10); !errors.Is(err, engine.ErrRunning) {
+		t.Errorf("Expected ErrRunning, got %v", err)
+	}
+	eng.Stop()
+	select {
+	case <-eng.Done():
+	default:
+		t.Fatal("Expected Stop to wait until the loop exits")
+	}
+	if err := eng.Err(); err != nil {
+		t.Errorf("Expected no error after Stop, got %v", err)
+	}
+}
+
+func TestRunContextCancel(t *testing.T) {
+	runWithTimeout(t, func(t *testing.T) {
+		eng := newCommandEngine()
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := eng.RunContext(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		for eng.GetTick() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-eng.Done()
+		if err := eng.Err(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+
+		// The engine can be started again right away
+		if err := eng.RunContext(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+		eng.Stop()
+	})
+}
+
+func TestRunRecoversUpdatePanic(t *testing.T) {
+	runWithTimeout(t, func(t *testing.T) {
+		eng := newCommandEngine()
+		eng.Subscribe(func(event engine.Event) {
+			if event.Type == engine.Landed {
+				panic("handler failed")
+			}
+		})
+		if err := eng.RunContext(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+		eng.SetVelocity(2, engine.Vector{Y: -10000}) // Lands on the floor in the update loop
+
+		<-eng.Done()
+		if err := eng.Err(); !errors.Is(err, engine.ErrUpdatePanic) {
+			t.Errorf("Expected ErrUpdatePanic, got %v", err)
+		}
+		if tick := eng.Step(0.01); tick == 0 {
+			t.Error("Expected the engine to stay usable after the panic")
+		}
+	})
+}
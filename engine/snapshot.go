@@ -0,0 +1,69 @@
+package engine
+
+// Read-only copy of the world with its tick and simulation time
+type publishedWorld struct {
+	world *World
+	tick  uint64
+	time  float64
+}
+
+// -- Public methods -- //
+
+// Get a read-only snapshot of the world, can be nil
+// Unlike GetWorld the snapshot is shared: the world is copied once after it changes
+// and the copy is returned to every caller until the next change (usually the next tick),
+// so it's cheap to get every frame, but it must not be changed, use GetWorld for a private copy
+func (engine *Engine) GetWorldView() *World {
+	world, _, _ := engine.snapshot()
+	return world
+}
+
+// Copy the world with its objects and their impulses
+// The copy doesn't share any memory with the original, so it can be read and changed
+// from any goroutine while the engine keeps updating the original
+func (world *World) Clone() *World {
+	copied := &World{
+		Gravity:  world.Gravity,
+		Boundary: world.Boundary,
+		Objects:  make(map[int]*Object, len(world.Objects)),
+	}
+	objects := make([]Object, 0, len(world.Objects)) // One allocation for all the objects
+	for id, obj := range world.Objects {
+		objects = append(objects, *obj)
+		clone := &objects[len(objects)-1]
+		clone.Impulses = obj.Impulses.clone()
+		copied.Objects[id] = clone
+	}
+	return copied
+}
+
+// -- Internal methods -- //
+
+// Get the read-only copy of the current world with its tick and simulation time,
+// copying the world under the read lock only if it changed since the last copy
+// Returns nil if there is no world
+func (engine *Engine) snapshot() (*World, uint64, float64) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	if engine.world == nil {
+		return nil, engine.tick, engine.simulationTime
+	}
+	published := engine.published.Load()
+	if published == nil {
+		published = &publishedWorld{world: engine.world.Clone(), tick: engine.tick, time: engine.simulationTime}
+		if !engine.published.CompareAndSwap(nil, published) {
+			published = engine.published.Load() // Another reader copied the same state first
+		}
+	}
+	return published.world, published.tick, published.time
+}
+
+// Copy the impulse chain
+func (imp *Impulse) clone() *Impulse {
+	var head *Impulse
+	for next := &head; imp != nil; imp = imp.Next {
+		*next = &Impulse{Direction: imp.Direction, Damping: imp.Damping}
+		next = &(*next).Next
+	}
+	return head
+}
@@ -0,0 +1,110 @@
+package engine_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestGetWorldReturnsSnapshot(t *testing.T) {
+	eng := newCommandEngine()
+	eng.AddImpulse(1, engine.Vector{X: 10}, 0.5)
+
+	world := eng.GetWorld()
+	world.Objects[1].Position.X = -1
+	world.Objects[1].Impulses.Direction.X = -1
+	delete(world.Objects, 2)
+	obj := eng.GetObject(1)
+	obj.Velocity.X = 100
+
+	if obj := eng.GetObject(1); obj.Position.X != 100 || obj.Velocity.X != 0 || obj.Impulses.Direction.X != 10 {
+		t.Errorf("Expected the engine object not to be changed through the copies, got %+v", obj)
+	}
+	if eng.GetObject(2) == nil || eng.GetObject(42) != nil {
+		t.Error("Expected the snapshot changes not to affect the world")
+	}
+}
+
+func TestGetWorldViewIsShared(t *testing.T) {
+	eng := newCommandEngine()
+	view := eng.GetWorldView()
+	if eng.GetWorldView() != view {
+		t.Error("Expected the same view while the world doesn't change")
+	}
+	world := eng.GetWorld()
+	world.Objects[1].Position.X = -1
+	if view.Objects[1].Position.X != 100 {
+		t.Errorf("Expected the private copy not to share the view, got position %v", view.Objects[1].Position)
+	}
+
+	// Every change publishes a new view and keeps the old one intact
+	eng.SetVelocity(1, engine.Vector{X: 10})
+	changed := eng.GetWorldView()
+	if changed == view || changed.Objects[1].Velocity.X != 10 || view.Objects[1].Velocity.X != 0 {
+		t.Errorf("Expected a new view with the velocity, got %v and the old one %v", changed.Objects[1].Velocity, view.Objects[1].Velocity)
+	}
+	eng.Step(1)
+	if stepped := eng.GetWorldView(); stepped == changed || stepped.Objects[1].Position.X != 110 {
+		t.Errorf("Expected a new view after the tick, got position %v", stepped.Objects[1].Position)
+	}
+	if (&engine.Engine{}).GetWorldView() != nil {
+		t.Error("Expected nil view without a world")
+	}
+}
+
+func TestReadOnlyCallsKeepTheView(t *testing.T) {
+	eng := newCommandEngine()
+	eng.SetEventQueueSize(8)
+	view := eng.GetWorldView()
+
+	// Queries, event polling and snapshots don't change the world, so the view isn't copied again
+	eng.Raycast(engine.Vector{X: 0, Y: 20}, engine.Vector{X: 1}, 1000, engine.QueryFilter{})
+	eng.QueryRect(engine.Vector{}, engine.Vector{X: 1000, Y: 1000}, engine.QueryFilter{})
+	eng.QueryRadius(engine.Vector{X: 100, Y: 20}, 50, engine.QueryFilter{})
+	eng.QueryNearest(engine.Vector{X: 100, Y: 20}, 1, engine.QueryFilter{})
+	eng.RewindRaycast(0, engine.Vector{X: 0, Y: 20}, engine.Vector{X: 1}, 1000, engine.QueryFilter{})
+	eng.DrainEvents()
+	eng.PollEvents(make([]engine.Event, 1))
+	eng.PushSnapshot(eng.GetWorld(), 1)
+	if eng.GetWorldView() != view {
+		t.Error("Expected the read-only calls to keep the published view")
+	}
+}
+
+func TestSnapshotsDuringRun(t *testing.T) {
+	runWithTimeout(t, func(t *testing.T) {
+		eng := newCommandEngine()
+		eng.Run(1)
+		t.Cleanup(eng.Stop)
+
+		// Run with -race to check the readers don't touch the live world
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					for _, obj := range eng.GetWorld().Objects {
+						_ = obj.Position
+					}
+					for _, obj := range eng.GetWorldView().Objects {
+						_ = obj.Position
+					}
+					if obj := eng.GetObject(2); obj != nil {
+						_ = obj.Velocity
+					}
+					eng.QueryRadius(engine.Vector{X: 100, Y: 20}, 500, engine.QueryFilter{}) // Shares the read lock
+					if _, err := engine.WorldFromBytes(eng.GetWorldBytes()); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+		for i := range 200 {
+			eng.SetVelocity(1, engine.Vector{X: float64(i)})
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,228 @@
+package engine
+
+import (
+	"math"
+	"sort"
+)
+
+// Number of spatial hash cells along the longest side of the world boundary
+const spatialHashCells = 128
+
+// Limit for the cell coordinates, keeps objects far outside the world
+// (or with infinite positions) from overflowing the cell keys
+const spatialHashMaxCell = 1 << 20
+
+// Key of a single spatial hash cell
+type cellKey struct {
+	x, y int
+}
+
+// Range of cells covered by an object's box
+type cellRange struct {
+	minX, minY, maxX, maxY int
+}
+
+// Object stored in the spatial hash with the cells it covers
+type spatialEntry struct {
+	obj   *Object
+	cells cellRange
+}
+
+// Spatial hash is a uniform grid broadphase index over the world objects
+// Each object is stored in every cell its box overlaps,
+// queries only visit the cells covered by the query box
+type spatialHash struct {
+	cellSize float64                    // Size of a single square cell
+	cells    map[cellKey][]spatialEntry // Objects in each cell
+	entries  map[int]spatialEntry       // Indexed objects by ID
+}
+
+// Create a new spatial hash with the cell size derived from the world boundary
+// Worlds without a boundary derive the cell size from the object sizes instead
+func newSpatialHash(boundary Vector, objects map[int]*Object) *spatialHash {
+	cellSize := math.Max(boundary.X, boundary.Y) / spatialHashCells
+	if cellSize < 1 || math.IsNaN(cellSize) || math.IsInf(cellSize, 0) {
+		cellSize = _objectCellSize(objects)
+	}
+	return &spatialHash{
+		cellSize: cellSize,
+		cells:    make(map[cellKey][]spatialEntry),
+		entries:  make(map[int]spatialEntry),
+	}
+}
+
+// Get the spatial index of the world, building it on first use
+func (world *World) spatialIndex() *spatialHash {
+	if world.index == nil {
+		world.index = newSpatialHash(world.Boundary, world.Objects)
+		world.index.sync(world.Objects)
+	}
+	return world.index
+}
+
+// Add or move the object in the spatial index, if the index is built
+func (world *World) indexUpsert(obj *Object) {
+	if world.index != nil {
+		world.index.upsert(obj)
+	}
+}
+
+// Remove the object from the spatial index, if the index is built
+func (world *World) indexRemove(id int) {
+	if world.index != nil {
+		world.index.remove(id)
+	}
+}
+
+// Convert a coordinate to the cell coordinate
+func (hash *spatialHash) cell(value float64) int {
+	c := math.Floor(value / hash.cellSize)
+	switch {
+	case math.IsNaN(c):
+		return 0
+	case c < -spatialHashMaxCell:
+		return -spatialHashMaxCell
+	case c > spatialHashMaxCell:
+		return spatialHashMaxCell
+	}
+	return int(c)
+}
+
+// Range of cells covered by the box
+func (hash *spatialHash) rangeOf(minX, minY, maxX, maxY float64) cellRange {
+	return cellRange{
+		minX: hash.cell(minX),
+		minY: hash.cell(minY),
+		maxX: hash.cell(maxX),
+		maxY: hash.cell(maxY),
+	}
+}
+
+// Range of cells covered by the object's box
+func (hash *spatialHash) rangeOfObject(obj *Object) cellRange {
+	return hash.rangeOf(obj.positionLeftX(), obj.positionBottomY(), obj.positionRightX(), obj.positionTopY())
+}
+
+// Add the object to the index or move it to the new cells
+// Does nothing if the object still covers the same cells
+func (hash *spatialHash) upsert(obj *Object) {
+	hash.place(spatialEntry{obj: obj, cells: hash.rangeOfObject(obj)})
+}
+
+// Put the entry to its cells, moving it from the previous ones
+func (hash *spatialHash) place(next spatialEntry) {
+	obj := next.obj
+	if prev, ok := hash.entries[obj.ID]; ok {
+		if prev == next {
+			return
+		}
+		hash.removeFromCells(obj.ID, prev.cells)
+	}
+	hash.entries[obj.ID] = next
+	for x := next.cells.minX; x <= next.cells.maxX; x++ {
+		for y := next.cells.minY; y <= next.cells.maxY; y++ {
+			key := cellKey{x, y}
+			hash.cells[key] = append(hash.cells[key], next)
+		}
+	}
+}
+
+// Remove the object from the index
+func (hash *spatialHash) remove(id int) {
+	if prev, ok := hash.entries[id]; ok {
+		hash.removeFromCells(id, prev.cells)
+		delete(hash.entries, id)
+	}
+}
+
+// Remove the object ID from every cell of the range
+func (hash *spatialHash) removeFromCells(id int, r cellRange) {
+	for x := r.minX; x <= r.maxX; x++ {
+		for y := r.minY; y <= r.maxY; y++ {
+			key := cellKey{x, y}
+			entries := hash.cells[key]
+			for i, other := range entries {
+				if other.obj.ID == id {
+					last := len(entries) - 1
+					entries[i] = entries[last]
+					entries[last] = spatialEntry{}
+					entries = entries[:last]
+					break
+				}
+			}
+			if len(entries) == 0 {
+				delete(hash.cells, key)
+			} else {
+				hash.cells[key] = entries
+			}
+		}
+	}
+}
+
+// Synchronize the index with the objects map
+// Objects changed directly in the map (not through the engine) are picked up here
+func (hash *spatialHash) sync(objects map[int]*Object) {
+	for _, obj := range objects {
+		hash.upsert(obj)
+	}
+	if len(hash.entries) == len(objects) {
+		return
+	}
+	for id, entry := range hash.entries {
+		if objects[id] != entry.obj {
+			hash.remove(id)
+		}
+	}
+}
+
+// Query the objects whose cells overlap the box
+// The result contains no duplicates but is not ordered,
+// candidates still have to be checked against the exact boxes
+func (hash *spatialHash) query(minX, minY, maxX, maxY float64, buf []*Object) []*Object {
+	q := hash.rangeOf(minX, minY, maxX, maxY)
+	buf = buf[:0]
+	if (q.maxX-q.minX+1)*(q.maxY-q.minY+1) > len(hash.entries) {
+		// Huge query boxes (long rays) visit the entries instead of the empty cells
+		for _, entry := range hash.entries {
+			if entry.cells.minX <= q.maxX && entry.cells.maxX >= q.minX &&
+				entry.cells.minY <= q.maxY && entry.cells.maxY >= q.minY {
+				buf = append(buf, entry.obj)
+			}
+		}
+		return buf
+	}
+	for x := q.minX; x <= q.maxX; x++ {
+		for y := q.minY; y <= q.maxY; y++ {
+			for _, entry := range hash.cells[cellKey{x, y}] {
+				// Objects covering several cells are reported only once,
+				// from the first cell shared with the query
+				if x == max(entry.cells.minX, q.minX) && y == max(entry.cells.minY, q.minY) {
+					buf = append(buf, entry.obj)
+				}
+			}
+		}
+	}
+	return buf
+}
+
+// Query the objects whose cells overlap the object's box
+func (hash *spatialHash) queryObject(obj *Object, buf []*Object) []*Object {
+	return hash.query(obj.positionLeftX(), obj.positionBottomY(), obj.positionRightX(), obj.positionTopY(), buf)
+}
+
+// Cell size for a world without a boundary: twice the median of the longest object sides,
+// so a typical object covers a few cells and huge terrain doesn't skew the size, at least 1
+func _objectCellSize(objects map[int]*Object) float64 {
+	sides := make([]float64, 0, len(objects))
+	for _, obj := range objects {
+		side := math.Max(obj.Size.X, obj.Size.Y)
+		if side > 0 && !math.IsInf(side, 0) {
+			sides = append(sides, side)
+		}
+	}
+	if len(sides) == 0 {
+		return 1
+	}
+	sort.Float64s(sides)
+	return math.Max(2*sides[len(sides)/2], 1)
+}
@@ -0,0 +1,103 @@
+package engine_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// newCrowdedWorld creates a world with the given number of projectiles and creatures
+// standing on the ground and flying in random directions.
+func newCrowdedWorld(projectiles, creatures int) *engine.World {
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+	boundary := engine.Vector{X: 20000, Y: 2000}
+	world := &engine.World{
+		Gravity:  9.8,
+		Boundary: boundary,
+		Objects:  make(map[int]*engine.Object, projectiles+creatures+1),
+	}
+	world.Objects[1] = &engine.Object{ID: 1, Type: engine.Terrain, Size: engine.Vector{X: boundary.X, Y: 20}, Position: engine.Vector{X: boundary.X / 2, Y: 10}}
+	id := 2
+	for range creatures {
+		world.Objects[id] = &engine.Object{
+			ID:            id,
+			Type:          engine.Creature,
+			Size:          engine.Vector{X: 24, Y: 64},
+			Position:      engine.Vector{X: rnd.Float64() * boundary.X, Y: 52 + rnd.Float64()*boundary.Y/2},
+			Velocity:      engine.Vector{X: rnd.Float64()*200 - 100},
+			GravityFactor: 1,
+		}
+		id++
+	}
+	for range projectiles {
+		world.Objects[id] = &engine.Object{
+			ID:            id,
+			Type:          engine.Projectile,
+			Size:          engine.Vector{X: 16, Y: 2},
+			Position:      engine.Vector{X: rnd.Float64() * boundary.X, Y: 20 + rnd.Float64()*boundary.Y},
+			Velocity:      engine.Vector{X: rnd.Float64()*2000 - 1000, Y: rnd.Float64()*400 - 200},
+			GravityFactor: 1,
+		}
+		id++
+	}
+	return world
+}
+
+func TestSetPositionMovesObjectInIndex(t *testing.T) {
+	wall := &engine.Object{ID: 1, Type: engine.Structure, Size: engine.Vector{X: 20, Y: 200}, Position: engine.Vector{X: 800, Y: 500}}
+	hero := &engine.Object{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 100, Y: 500}}
+
+	eng := &engine.Engine{}
+	world := newTestWorld(wall, hero)
+	eng.SetWorld(world, 0)
+	eng.Step(0.01)
+
+	eng.SetPosition(hero.ID, engine.Vector{X: 785, Y: 500})
+	eng.SetVelocity(hero.ID, engine.Vector{X: 100})
+	eng.Step(0.01)
+
+	if hero.Position.X != 780 {
+		t.Errorf("Expected creature to be blocked by the wall at 780, got %f", hero.Position.X)
+	}
+
+	eng.RemoveObject(wall.ID)
+	eng.SetVelocity(hero.ID, engine.Vector{X: 100})
+	eng.Step(0.01)
+	if hero.Position.X <= 780 {
+		t.Errorf("Expected creature to move after the wall was removed, got %f", hero.Position.X)
+	}
+}
+
+func TestSpatialIndexWithoutBoundary(t *testing.T) {
+	world := newTestWorld()
+	world.Boundary = engine.Vector{}
+	for i := range 10 {
+		id := i + 1
+		world.Objects[id] = &engine.Object{ID: id, Type: engine.Item, Size: engine.Vector{X: 40, Y: 40}, Position: engine.Vector{X: float64(i) * 100, Y: 20}}
+	}
+	eng := &engine.Engine{}
+	eng.SetWorld(world, 0)
+
+	rect := eng.QueryRect(engine.Vector{X: 150, Y: 0}, engine.Vector{X: 450, Y: 40}, engine.QueryFilter{})
+	if len(rect) != 3 || rect[0].ID != 3 || rect[2].ID != 5 {
+		t.Errorf("Expected objects 3 to 5 in the rectangle, got %v", rect)
+	}
+	nearest := eng.QueryNearest(engine.Vector{X: 900, Y: 20}, 2, engine.QueryFilter{})
+	if len(nearest) != 2 || nearest[0].ID != 9 || nearest[1].ID != 10 {
+		t.Errorf("Expected objects 9 and 10 nearest to the point, got %v", nearest)
+	}
+}
+
+// BenchmarkUpdateCrowdedWorld measures a single tick of 5,000 projectiles and 500 creatures,
+// which has to stay well within a 16 ms tick.
+func BenchmarkUpdateCrowdedWorld(b *testing.B) {
+	world := newCrowdedWorld(5000, 500)
+	eng := &engine.Engine{}
+	eng.SetWorld(world, 0)
+
+	b.ResetTimer()
+	for range b.N {
+		eng.Step(0.016)
+	}
+}
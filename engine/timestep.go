@@ -0,0 +1,125 @@
+package engine
+
+import "math"
+
+// Default maximum number of fixed steps per update
+// Protects from the "spiral of death" when the simulation can't keep up
+const defaultMaxSubsteps = 8
+
+// Set the fixed simulation step in milliseconds
+// In the fixed-step mode the elapsed time is accumulated
+// and the world is advanced by exactly stepMS at a time,
+// at most maxSubsteps times per update, the rest is dropped
+// Zero or negative stepMS switches back to the variable step
+func (engine *Engine) SetFixedStep(stepMS float64, maxSubsteps int) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	if stepMS <= 0 {
+		engine.fixedStep = 0
+	} else {
+		engine.fixedStep = stepMS / 1000
+	}
+	if maxSubsteps < 1 {
+		maxSubsteps = defaultMaxSubsteps
+	}
+	engine.maxSubsteps = maxSubsteps
+	engine.resetTimestep()
+}
+
+// Get the interpolation fraction [0, 1) between the previous and current state
+// Renderers can blend positions as previous + (current - previous) * alpha
+// Always 0 in the variable-step mode
+func (engine *Engine) GetInterpolationAlpha() float64 {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	return engine.alpha
+}
+
+// Get the object position blended between the previous and current fixed step
+// Returns the current position for objects added after the last step
+// and in the variable-step mode
+func (engine *Engine) GetInterpolatedPosition(id int) (Vector, bool) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+	obj := engine.getObject(id)
+	if obj == nil {
+		return Vector{}, false
+	}
+	prev, ok := engine.previous[id]
+	if !ok {
+		return obj.Position, true
+	}
+	return Vector{
+		X: prev.X + (obj.Position.X-prev.X)*engine.alpha,
+		Y: prev.Y + (obj.Position.Y-prev.Y)*engine.alpha,
+	}, true
+}
+
+// Advance the world by the real elapsed time in seconds, the same way as an update of the loop started by Run:
+// the time is scaled by the time scale, clamped to the maximum elapsed time
+// and split into fixed steps in the fixed-step mode, nothing happens while paused
+// Drives the engine from an external frame loop (e.g. a vsync callback) instead of Run
+// Returns the new tick number
+func (engine *Engine) StepElapsed(elapsed float64) uint64 {
+	defer engine.dispatchEvents()
+	engine.lock()
+	defer engine.mutex.Unlock()
+	engine.stepElapsed(elapsed)
+	return engine.tick
+}
+
+// -- Internal methods -- //
+
+// Advance the world by the real elapsed time unless paused
+func (engine *Engine) stepElapsed(elapsed float64) {
+	if engine.paused || !(elapsed > 0) {
+		return
+	}
+	engine.advance(engine.scaleElapsed(elapsed))
+}
+
+// Advance the world by the elapsed time,
+// in fixed steps if the fixed-step mode is enabled
+func (engine *Engine) advance(elapsed float64) {
+	step := engine.fixedStep
+	if step <= 0 {
+		engine.step(elapsed)
+		return
+	}
+
+	engine.accumulator += elapsed
+	for substeps := 0; engine.accumulator >= step; substeps++ {
+		if substeps >= engine.maxSubsteps {
+			// Drop the time the simulation can't catch up with
+			engine.accumulator = math.Mod(engine.accumulator, step)
+			break
+		}
+		engine.storePrevious()
+		engine.step(step)
+		engine.accumulator -= step
+	}
+	engine.alpha = engine.accumulator / step
+}
+
+// Store the object positions before the fixed step for the interpolation
+func (engine *Engine) storePrevious() {
+	world := engine.world
+	if world == nil {
+		return
+	}
+	if engine.previous == nil {
+		engine.previous = make(map[int]Vector, len(world.Objects))
+	} else {
+		clear(engine.previous)
+	}
+	for id, obj := range world.Objects {
+		engine.previous[id] = obj.Position
+	}
+}
+
+// Reset the accumulated time and the interpolation state
+func (engine *Engine) resetTimestep() {
+	engine.accumulator = 0
+	engine.alpha = 0
+	engine.previous = nil
+}
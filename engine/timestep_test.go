@@ -0,0 +1,44 @@
+package engine_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+func TestFixedStepAdvancesByWholeSteps(t *testing.T) {
+	spark := &engine.Object{ID: 1, Type: engine.Effect, Size: engine.Vector{X: 1, Y: 1}, Position: engine.Vector{X: 0, Y: 500}, Velocity: engine.Vector{X: 100}}
+
+	eng := &engine.Engine{}
+	eng.SetWorld(newTestWorld(spark), 0)
+	eng.SetFixedStep(10, 4)
+
+	// Every fixed step of 10 ms moves the effect by exactly 1 unit, the rest is accumulated
+	if tick := eng.StepElapsed(0.035); tick != 3 {
+		t.Fatalf("Expected 3 fixed steps in 35 ms, got %d", tick)
+	}
+	if position := eng.GetObject(spark.ID).Position.X; math.Abs(position-3) > 1e-9 {
+		t.Errorf("Expected position to advance by whole fixed steps, got %f", position)
+	}
+	if alpha := eng.GetInterpolationAlpha(); math.Abs(alpha-0.5) > 1e-9 {
+		t.Errorf("Expected interpolation alpha 0.5, got %f", alpha)
+	}
+	interpolated, ok := eng.GetInterpolatedPosition(spark.ID)
+	if !ok || math.Abs(interpolated.X-2.5) > 1e-9 {
+		t.Errorf("Expected interpolated position halfway between the previous and current step, got %f", interpolated.X)
+	}
+
+	// The accumulated 5 ms complete the next step
+	if tick := eng.StepElapsed(0.005); tick != 4 {
+		t.Errorf("Expected the accumulated time to complete a step, got tick %d", tick)
+	}
+
+	// A long stall runs at most maxSubsteps steps and drops the rest
+	if tick := eng.StepElapsed(0.2); tick != 8 {
+		t.Errorf("Expected at most 4 fixed steps per update, got tick %d", tick)
+	}
+	if alpha := eng.GetInterpolationAlpha(); alpha < 0 || alpha >= 1 {
+		t.Errorf("Expected interpolation alpha in [0, 1), got %f", alpha)
+	}
+}
@@ -1,7 +1,5 @@
 package engine
 
-import "math"
-
 // Calculate physics and update object positions
 func (engine *Engine) update(elapsed float64) {
 	if elapsed <= 0 {
@@ -13,168 +11,121 @@ func (engine *Engine) update(elapsed float64) {
 		return
 	}
 
-	// Update positions of all objects
-	for _, obj := range world.Objects {
-		switch obj.Type {
-		case Projectile:
-			obj._updateProjectile(world, elapsed)
-		case Effect:
-			obj._updateEffect(world, elapsed)
-		case Creature:
-			obj._updateCreature(world, elapsed)
-		case Item:
-			obj._updateItem(world, elapsed)
-		case Structure:
-			obj._updateStructure(world, elapsed)
-		case Terrain:
-			obj._updateTerrain(world, elapsed)
-		case Other:
-			obj._updateOther(world, elapsed)
-		}
-	}
+	integrator := engine.integrator
 
-	// TODO: Add collision detection and response here
-	// impulse damping during collisions
-}
+	// Pick up objects changed directly in the world since the last update
+	index := world.spatialIndex()
+	index.sync(world.Objects)
 
-// Apply gravity to an object
-func _applyGravity(obj *Object, gravity float64) {
-	if obj.GravityFactor != 0 {
-		obj.Velocity.Y += -gravity * obj.GravityFactor
-	}
-}
+	// Remember where the fast objects start, to sweep them after the integration
+	engine.starts = _continuousStarts(world, engine.starts)
+	starts := engine.starts
 
-// Apply impulses to an object with elapsed time
-func _applyImpulses(obj *Object, elapsed float64) {
-	const negligibleImpulse = negligibleFloat // Threshold for removing negligible impulses
-
-	var prev *Impulse
-	current := obj.Impulses
-
-	for current != nil {
-		// Apply impulse to velocity, scaled by elapsed time
-		obj.Velocity.X += current.Direction.X * elapsed
-		obj.Velocity.Y += current.Direction.Y * elapsed
-
-		// Apply damping to the impulse based on elapsed time
-		damping := current.Damping // Damping factor
-		switch {
-		case damping == 1:
-			// No damping
-		case damping <= negligibleImpulse:
-			// Immediate damping to zero
-			current.Direction.X = 0
-			current.Direction.Y = 0
-		default:
-			// Apply damping to the impulse direction
-			current.Direction.X *= math.Pow(damping, elapsed)
-			current.Direction.Y *= math.Pow(damping, elapsed)
+	// Update positions of all objects, in parallel for the huge worlds (see SetWorkers)
+	// and move the integrated objects to their new cells
+	if workers := engine.integrationWorkers(len(world.Objects)); workers > 1 {
+		engine.updateParallel(world, elapsed, integrator, workers)
+	} else {
+		for _, obj := range world.Objects {
+			obj._update(world, elapsed, integrator)
 		}
+		index.sync(world.Objects)
+	}
 
-		// Check if the impulse has decayed to negligible values
-		if math.Abs(current.Direction.X) < negligibleImpulse && math.Abs(current.Direction.Y) < negligibleImpulse {
-			// Remove impulse from the list
-			if prev == nil {
-				obj.Impulses = current.Next
-			} else {
-				prev.Next = current.Next
-			}
-			current = current.Next
-			continue
-		}
+	// Continuous collision detection, fast objects don't tunnel through thin ones
+	_resolveContinuous(world, starts)
+
+	// Collision detection and response
+	_resolveCollisions(world)
+}
 
-		// Move to the next impulse
-		prev = current
-		current = current.Next
+// Update the object based on its type
+func (obj *Object) _update(world *World, elapsed float64, integrator Integrator) {
+	switch obj.Type {
+	case Projectile:
+		obj._updateProjectile(world, elapsed, integrator)
+	case Effect:
+		obj._updateEffect(world, elapsed, integrator)
+	case Creature:
+		obj._updateCreature(world, elapsed, integrator)
+	case Item:
+		obj._updateItem(world, elapsed, integrator)
+	case Structure:
+		obj._updateStructure(world, elapsed)
+	case Terrain:
+		obj._updateTerrain(world, elapsed)
+	case Other:
+		obj._updateOther(world, elapsed)
 	}
 }
 
-// Extrapolate object position based on velocity and elapsed time
-func _extrapolatePosition(obj *Object, elapsed float64) {
-	obj.Position.X += obj.Velocity.X * elapsed
-	obj.Position.Y += obj.Velocity.Y * elapsed
+// Move the object by the displacement
+func _extrapolatePosition(obj *Object, displacement Vector) {
+	obj.Position.X += displacement.X
+	obj.Position.Y += displacement.Y
 }
 
 // Update projectiles (such as arrow) based on physics, gravity, and collisions
-func (obj *Object) _updateProjectile(world *World, elapsed float64) {
-	// Apply gravity
-	_applyGravity(obj, world.Gravity)
-
-	// Apply impulses with elapsed time
-	_applyImpulses(obj, elapsed)
+func (obj *Object) _updateProjectile(world *World, elapsed float64, integrator Integrator) {
+	// Integrate gravity, impulses and velocity over the elapsed time,
+	// slow down in the air and slide along the ground with friction
+	displacement := _integrate(obj, world.Gravity, elapsed, integrator, _standingFriction(obj, world, false))
 
 	// Extrapolate object position based on velocity
-	_extrapolatePosition(obj, elapsed)
+	_extrapolatePosition(obj, displacement)
 
-	// Stop object if it hits the ground and moving downward
-	if obj.onTheFloor() && obj.movingDownward() {
-		obj.Velocity.Y = 0
-		obj.Position.Y = 0
-	}
+	// Stop or bounce object if it hits the ground and moving downward
+	_landOnFloor(obj)
 }
 
 // Update effects and particles (such as explosion) based on physics, gravity, and collisions
-func (obj *Object) _updateEffect(world *World, elapsed float64) {
-	// Apply gravity
-	_applyGravity(obj, world.Gravity)
-
-	// Apply impulses with elapsed time
-	_applyImpulses(obj, elapsed)
+func (obj *Object) _updateEffect(world *World, elapsed float64, integrator Integrator) {
+	// Integrate gravity, impulses and velocity over the elapsed time, slow down in the air
+	displacement := _integrate(obj, world.Gravity, elapsed, integrator, 0)
 
 	// Extrapolate object position based on velocity
-	_extrapolatePosition(obj, elapsed)
+	_extrapolatePosition(obj, displacement)
 }
 
 // Update creatures (such as player) based on physics, gravity, and collisions
-func (obj *Object) _updateCreature(world *World, elapsed float64) {
-	// Apply gravity
-	_applyGravity(obj, world.Gravity)
-
-	// Apply impulses with elapsed time
-	_applyImpulses(obj, elapsed)
-
-	// Extrapolate object position based on velocity
-	_extrapolatePosition(obj, elapsed)
+func (obj *Object) _updateCreature(world *World, elapsed float64, integrator Integrator) {
+	// Integrate gravity, impulses and velocity over the elapsed time,
+	// slow down in the air and slide along the ground or the static object with friction
+	displacement := _integrate(obj, world.Gravity, elapsed, integrator, _standingFriction(obj, world, true))
 
-	// Clamp to world boundaries and stop object if it hits the ground or walls
-	obj.Position.X = clamp(obj.Position.X, obj.Size.X/2, world.Boundary.X-obj.Size.X/2)
-	obj.Position.Y = clamp(obj.Position.Y, 0, world.Boundary.Y-obj.Size.Y)
+	// Extrapolate object position based on velocity,
+	// land on, bump into and hit the head against terrain and structures
+	_moveWithStatics(obj, world, displacement)
 
-	// Stop object if it hits the ground and moving downward
-	if obj.onTheFloor() && obj.movingDownward() {
-		obj.Velocity.Y = 0
-		obj.Position.Y = 0
-	}
+	// Clamp to world boundaries, stop or bounce object if it hits the ground or walls
+	_keepInBoundary(obj, world.Boundary)
 }
 
 // Update items (such as coins) based on physics, gravity, and collisions
-func (obj *Object) _updateItem(world *World, elapsed float64) {
-	// Apply gravity
-	_applyGravity(obj, world.Gravity)
+func (obj *Object) _updateItem(world *World, elapsed float64, integrator Integrator) {
+	// Integrate gravity, impulses and velocity over the elapsed time,
+	// slow down in the air and slide along the ground or the static object with friction
+	displacement := _integrate(obj, world.Gravity, elapsed, integrator, _standingFriction(obj, world, true))
 
-	// Apply impulses with elapsed time
-	_applyImpulses(obj, elapsed)
+	// Extrapolate object position based on velocity,
+	// land on, bump into and hit the head against terrain and structures
+	_moveWithStatics(obj, world, displacement)
 
-	// Extrapolate object position based on velocity
-	obj.Position.X += obj.Velocity.X * elapsed
-	obj.Position.Y += obj.Velocity.Y * elapsed
-
-	// Clamp to world boundaries and stop object if it hits the ground or walls
-	obj.Position.X = clamp(obj.Position.X, obj.Size.X/2, world.Boundary.X-obj.Size.X/2)
-	obj.Position.Y = clamp(obj.Position.Y, 0, world.Boundary.Y-obj.Size.Y)
-
-	// Stop object if it hits the ground and moving downward
-	if obj.onTheFloor() && obj.movingDownward() {
-		obj.Velocity.Y = 0
-		obj.Position.Y = 0
-	}
+	// Clamp to world boundaries, stop or bounce object if it hits the ground or walls
+	_keepInBoundary(obj, world.Boundary)
 }
 
 // Update structures (such as walls), no physics or gravity applied
-func (obj *Object) _updateStructure(world *World, elapsed float64) {}
+// Structures are static solid colliders and never move
+func (obj *Object) _updateStructure(world *World, elapsed float64) {
+	_makeStatic(obj)
+}
 
 // Update terrain (such as ground), no physics or gravity applied
-func (obj *Object) _updateTerrain(world *World, elapsed float64) {}
+// Terrain is a static solid collider and never moves
+func (obj *Object) _updateTerrain(world *World, elapsed float64) {
+	_makeStatic(obj)
+}
 
 // Update unknown objects, no physics or gravity applied
 func (obj *Object) _updateOther(world *World, elapsed float64) {}
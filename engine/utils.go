@@ -1,5 +1,7 @@
 package engine
 
+import "sort"
+
 // Clamp a value between a min and max
 func clamp(val float64, minValue float64, maxValue float64) float64 {
 	if val < minValue {
@@ -10,3 +12,18 @@ func clamp(val float64, minValue float64, maxValue float64) float64 {
 	}
 	return val
 }
+
+// Get the object IDs sorted in ascending order
+func sortedObjectIDs(objects map[int]*Object) []int {
+	ids := make([]int, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Sort the objects by ID in ascending order
+func sortObjectsByID(objects []*Object) {
+	sort.Slice(objects, func(i, j int) bool { return objects[i].ID < objects[j].ID })
+}
@@ -0,0 +1,448 @@
+package engine
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/plugfox/slash-engine-go/generated/Game"
+)
+
+// ErrInvalidWorld is returned when the world bytes are truncated, malformed or inconsistent
+var ErrInvalidWorld = errors.New("engine: invalid world data")
+
+// Maximum length of an object impulse chain accepted from the bytes
+const maxImpulseChain = 256
+
+// Sizes of the FlatBuffers primitives in bytes
+const (
+	sizeUOffset = 4
+	sizeSOffset = 4
+	sizeVOffset = 2
+	sizeVector  = 16 // Game.Vector struct, two doubles
+)
+
+// Checks a FlatBuffers buffer against the schema before any generated accessor reads it
+type verifier struct {
+	buf     []byte
+	invalid error // Error wrapped by the failures, ErrInvalidWorld or ErrInvalidCommand
+}
+
+// Location of a verified table and its vtable
+type verifiedTable struct {
+	pos       int // Table start
+	size      int // Table size from the vtable
+	vtable    int // Vtable start
+	vtableLen int // Vtable size
+}
+
+// Verify the root World table, its header, objects and their impulse chains
+func _verifyWorld(data []byte) error {
+	v := verifier{buf: data, invalid: ErrInvalidWorld}
+	root, err := v.root()
+	if err != nil {
+		return err
+	}
+	if err := v.scalar(root, 0, 8, "world gravity"); err != nil {
+		return err
+	}
+	if err := v.scalar(root, 1, sizeVector, "world boundary"); err != nil {
+		return err
+	}
+	if offset := v.field(root, 1); offset != 0 && !v.finiteVector(root.pos+offset) {
+		return fmt.Errorf("%w: boundary is not finite", v.invalid)
+	}
+	count, start, err := v.vector(root, 2, sizeUOffset, "world objects")
+	if err != nil {
+		return err
+	}
+
+	if offset := v.field(root, 3); offset != 0 {
+		if err := v.verifyHeader(root.pos + offset); err != nil {
+			return err
+		}
+	}
+
+	ids := make(map[int32]bool, count)
+	for i := 0; i < count; i++ {
+		obj, err := v.indirect(start+i*sizeUOffset, "object")
+		if err != nil {
+			return err
+		}
+		id, err := v.verifyObject(obj)
+		if err != nil {
+			return err
+		}
+		if ids[id] {
+			return fmt.Errorf("%w: duplicate object id %d", v.invalid, id)
+		}
+		ids[id] = true
+	}
+	return nil
+}
+
+// Verify the root WorldDelta table, its header, added objects and object deltas
+func _verifyWorldDelta(data []byte) error {
+	v := verifier{buf: data, invalid: ErrInvalidWorld}
+	root, err := v.root()
+	if err != nil {
+		return err
+	}
+	if err := v.scalar(root, 0, 8, "delta gravity"); err != nil {
+		return err
+	}
+	if err := v.scalar(root, 1, sizeVector, "delta boundary"); err != nil {
+		return err
+	}
+	if offset := v.field(root, 1); offset != 0 && !v.finiteVector(root.pos+offset) {
+		return fmt.Errorf("%w: boundary is not finite", v.invalid)
+	}
+	if _, _, err := v.vector(root, 3, 4, "delta removed"); err != nil {
+		return err
+	}
+	offset := v.field(root, 5)
+	if offset == 0 {
+		return fmt.Errorf("%w: delta has no header", v.invalid)
+	}
+	if err := v.verifyHeader(root.pos + offset); err != nil {
+		return err
+	}
+
+	ids := make(map[int32]bool)
+	count, start, err := v.vector(root, 2, sizeUOffset, "delta added")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		obj, err := v.indirect(start+i*sizeUOffset, "object")
+		if err != nil {
+			return err
+		}
+		id, err := v.verifyObject(obj)
+		if err != nil {
+			return err
+		}
+		if ids[id] {
+			return fmt.Errorf("%w: duplicate object id %d", v.invalid, id)
+		}
+		ids[id] = true
+	}
+
+	count, start, err = v.vector(root, 4, sizeUOffset, "delta changed")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		delta, err := v.indirect(start+i*sizeUOffset, "object delta")
+		if err != nil {
+			return err
+		}
+		id, err := v.verifyObjectDelta(delta)
+		if err != nil {
+			return err
+		}
+		if ids[id] {
+			return fmt.Errorf("%w: duplicate object id %d", v.invalid, id)
+		}
+		ids[id] = true
+	}
+	return nil
+}
+
+// Verify the root CommandBatch table, its commands and their bodies,
+// including the objects of the spawn commands and their impulse chains
+func _verifyCommandBatch(data []byte) error {
+	v := verifier{buf: data, invalid: ErrInvalidCommand}
+	root, err := v.root()
+	if err != nil {
+		return err
+	}
+	count, start, err := v.vector(root, 0, sizeUOffset, "batch commands")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		command, err := v.indirect(start+i*sizeUOffset, "command")
+		if err != nil {
+			return err
+		}
+		if err := v.verifyCommand(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// -- Internal methods -- //
+
+// Verify the client command and its body, unknown command types are left to the decoder
+func (v *verifier) verifyCommand(command verifiedTable) error {
+	// Sequence, tick, union type and union body of Game.ClientCommand by slot
+	for slot, size := range [...]int{4, 8, 1, sizeUOffset} {
+		if err := v.scalar(command, slot, size, "command field"); err != nil {
+			return err
+		}
+	}
+	kind := Game.CommandNONE
+	if offset := v.field(command, 2); offset != 0 {
+		kind = Game.Command(v.buf[command.pos+offset])
+	}
+	offset := v.field(command, 3)
+	if offset == 0 {
+		return nil
+	}
+	body, err := v.indirect(command.pos+offset, "command body")
+	if err != nil {
+		return err
+	}
+
+	// Fields of the command body by slot
+	var sizes []int
+	switch kind {
+	case Game.CommandMoveCommand:
+		sizes = []int{4, sizeVector}
+	case Game.CommandJumpCommand:
+		sizes = []int{4, 8}
+	case Game.CommandImpulseCommand:
+		sizes = []int{4, sizeVector, 8}
+	case Game.CommandSpawnCommand:
+		sizes = []int{sizeUOffset}
+	case Game.CommandRemoveCommand:
+		sizes = []int{4}
+	}
+	for slot, size := range sizes {
+		if err := v.scalar(body, slot, size, "command body field"); err != nil {
+			return err
+		}
+	}
+	if kind == Game.CommandSpawnCommand {
+		if offset := v.field(body, 0); offset != 0 {
+			obj, err := v.indirect(body.pos+offset, "spawned object")
+			if err != nil {
+				return err
+			}
+			if _, err := v.verifyObject(obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Verify the object fields and return its id
+func (v *verifier) verifyObject(obj verifiedTable) (int32, error) {
+	// Scalar and struct fields of Game.Object by slot
+	sizes := [...]int{4, 4, 1, sizeVector, sizeVector, sizeVector, sizeVector, 8, sizeUOffset, 1, 8, 8, 8}
+	for slot, size := range sizes {
+		if err := v.scalar(obj, slot, size, "object field"); err != nil {
+			return 0, err
+		}
+	}
+
+	id := int32(0)
+	if offset := v.field(obj, 0); offset != 0 {
+		id = int32(v.readUint32(obj.pos + offset))
+	}
+	if offset := v.field(obj, 1); offset != 0 {
+		if t := ObjectType(int32(v.readUint32(obj.pos + offset))); t < Other || t > Item {
+			return 0, fmt.Errorf("%w: object %d has unknown type %d", v.invalid, id, t)
+		}
+	}
+	for slot := 3; slot <= 6; slot++ {
+		if offset := v.field(obj, slot); offset != 0 && !v.finiteVector(obj.pos+offset) {
+			return 0, fmt.Errorf("%w: object %d has a non-finite vector", v.invalid, id)
+		}
+	}
+
+	if err := v.verifyImpulses(obj, 8, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Verify the fields of the object delta and return its id
+func (v *verifier) verifyObjectDelta(delta verifiedTable) (int32, error) {
+	// Scalar and struct fields of Game.ObjectDelta by slot
+	sizes := [...]int{4, 4, 4, 1, sizeVector, sizeVector, sizeVector, sizeVector, 8, sizeUOffset, 1, 8, 8, 8}
+	for slot, size := range sizes {
+		if err := v.scalar(delta, slot, size, "object delta field"); err != nil {
+			return 0, err
+		}
+	}
+
+	id := int32(0)
+	if offset := v.field(delta, 0); offset != 0 {
+		id = int32(v.readUint32(delta.pos + offset))
+	}
+	if offset := v.field(delta, 2); offset != 0 {
+		if t := ObjectType(int32(v.readUint32(delta.pos + offset))); t < Other || t > Item {
+			return 0, fmt.Errorf("%w: object delta %d has unknown type %d", v.invalid, id, t)
+		}
+	}
+	for slot := 4; slot <= 7; slot++ {
+		if offset := v.field(delta, slot); offset != 0 && !v.finiteVector(delta.pos+offset) {
+			return 0, fmt.Errorf("%w: object delta %d has a non-finite vector", v.invalid, id)
+		}
+	}
+	if err := v.verifyImpulses(delta, 9, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Verify the impulse chain starting at the slot of the table
+func (v *verifier) verifyImpulses(table verifiedTable, slot int, id int32) error {
+	// Impulse chain, offsets only point forward so it can't loop, but it can be long
+	link := table
+	for depth := 0; ; depth++ {
+		offset := v.field(link, slot)
+		if offset == 0 {
+			break
+		}
+		if depth >= maxImpulseChain {
+			return fmt.Errorf("%w: object %d has more than %d impulses", v.invalid, id, maxImpulseChain)
+		}
+		next, err := v.indirect(link.pos+offset, "impulse")
+		if err != nil {
+			return err
+		}
+		if err := v.scalar(next, 0, sizeVector, "impulse direction"); err != nil {
+			return err
+		}
+		if err := v.scalar(next, 1, 8, "impulse damping"); err != nil {
+			return err
+		}
+		if err := v.scalar(next, 2, sizeUOffset, "impulse next"); err != nil {
+			return err
+		}
+		link, slot = next, 2
+	}
+	return nil
+}
+
+// Verify the world header table the offset at the position points to
+func (v *verifier) verifyHeader(at int) error {
+	header, err := v.indirect(at, "header")
+	if err != nil {
+		return err
+	}
+	for slot, size := range [...]int{4, 4, sizeUOffset, 8, 8} {
+		if err := v.scalar(header, slot, size, "header field"); err != nil {
+			return err
+		}
+	}
+	if offset := v.field(header, 2); offset != 0 {
+		return v.verifyString(header.pos+offset, "engine version")
+	}
+	return nil
+}
+
+// Verify the root offset and the root table
+func (v *verifier) root() (verifiedTable, error) {
+	if len(v.buf) < sizeUOffset {
+		return verifiedTable{}, fmt.Errorf("%w: %d bytes is too short", v.invalid, len(v.buf))
+	}
+	return v.indirect(0, "root")
+}
+
+// Follow the unsigned offset stored at the position to a table and verify it
+func (v *verifier) indirect(at int, name string) (verifiedTable, error) {
+	if !v.inBounds(at, sizeUOffset) {
+		return verifiedTable{}, fmt.Errorf("%w: %s offset out of bounds", v.invalid, name)
+	}
+	offset := v.readUint32(at)
+	if offset == 0 {
+		return verifiedTable{}, fmt.Errorf("%w: %s offset is zero", v.invalid, name)
+	}
+	return v.table(at+int(offset), name)
+}
+
+// Verify the table header and its vtable at the position
+func (v *verifier) table(pos int, name string) (verifiedTable, error) {
+	if pos < 0 || !v.inBounds(pos, sizeSOffset) {
+		return verifiedTable{}, fmt.Errorf("%w: %s table out of bounds", v.invalid, name)
+	}
+	vtable := pos - int(int32(v.readUint32(pos)))
+	if vtable < 0 || !v.inBounds(vtable, 2*sizeVOffset) {
+		return verifiedTable{}, fmt.Errorf("%w: %s vtable out of bounds", v.invalid, name)
+	}
+	vtableLen := int(binary.LittleEndian.Uint16(v.buf[vtable:]))
+	size := int(binary.LittleEndian.Uint16(v.buf[vtable+sizeVOffset:]))
+	if vtableLen < 2*sizeVOffset || vtableLen%sizeVOffset != 0 || !v.inBounds(vtable, vtableLen) {
+		return verifiedTable{}, fmt.Errorf("%w: %s vtable is malformed", v.invalid, name)
+	}
+	if size < sizeSOffset || !v.inBounds(pos, size) {
+		return verifiedTable{}, fmt.Errorf("%w: %s table is truncated", v.invalid, name)
+	}
+	return verifiedTable{pos: pos, size: size, vtable: vtable, vtableLen: vtableLen}, nil
+}
+
+// Get the offset of the field in the table, 0 if the field is absent
+func (v *verifier) field(table verifiedTable, slot int) int {
+	entry := 2*sizeVOffset + slot*sizeVOffset
+	if entry+sizeVOffset > table.vtableLen {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint16(v.buf[table.vtable+entry:]))
+}
+
+// Verify that the inline field fits into its table
+func (v *verifier) scalar(table verifiedTable, slot int, size int, name string) error {
+	offset := v.field(table, slot)
+	if offset != 0 && (offset < sizeSOffset || offset+size > table.size) {
+		return fmt.Errorf("%w: %s is out of its table", v.invalid, name)
+	}
+	return nil
+}
+
+// Verify the vector field and return its length and the position of the first element
+func (v *verifier) vector(table verifiedTable, slot int, elementSize int, name string) (int, int, error) {
+	if err := v.scalar(table, slot, sizeUOffset, name); err != nil {
+		return 0, 0, err
+	}
+	offset := v.field(table, slot)
+	if offset == 0 {
+		return 0, 0, nil
+	}
+	at := table.pos + offset
+	pos := at + int(v.readUint32(at))
+	if pos <= at || !v.inBounds(pos, sizeUOffset) {
+		return 0, 0, fmt.Errorf("%w: %s out of bounds", v.invalid, name)
+	}
+	length := uint64(v.readUint32(pos))
+	if length*uint64(elementSize) > uint64(len(v.buf)-pos-sizeUOffset) {
+		return 0, 0, fmt.Errorf("%w: %s length %d is out of bounds", v.invalid, name, length)
+	}
+	return int(length), pos + sizeUOffset, nil
+}
+
+// Verify the string the offset at the position points to, including its zero terminator
+func (v *verifier) verifyString(at int, name string) error {
+	pos := at + int(v.readUint32(at))
+	if pos <= at || !v.inBounds(pos, sizeUOffset) {
+		return fmt.Errorf("%w: %s out of bounds", v.invalid, name)
+	}
+	if length := uint64(v.readUint32(pos)); length+1 > uint64(len(v.buf)-pos-sizeUOffset) {
+		return fmt.Errorf("%w: %s length %d is out of bounds", v.invalid, name, length)
+	}
+	return nil
+}
+
+// Check that size bytes from the position are inside the buffer
+func (v *verifier) inBounds(pos int, size int) bool {
+	return pos >= 0 && size >= 0 && pos <= len(v.buf)-size
+}
+
+// Read a little-endian uint32 at the verified position
+func (v *verifier) readUint32(pos int) uint32 {
+	return binary.LittleEndian.Uint32(v.buf[pos:])
+}
+
+// Check that both doubles of the vector at the verified position are finite
+func (v *verifier) finiteVector(pos int) bool {
+	return _isFiniteVector(Vector{
+		X: math.Float64frombits(binary.LittleEndian.Uint64(v.buf[pos:])),
+		Y: math.Float64frombits(binary.LittleEndian.Uint64(v.buf[pos+8:])),
+	})
+}
@@ -0,0 +1,86 @@
+package engine_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/plugfox/slash-engine-go/engine"
+	"github.com/plugfox/slash-engine-go/generated/Game"
+)
+
+// packWorld serializes the world built with the generated object API.
+func packWorld(world *Game.WorldT) []byte {
+	builder := flatbuffers.NewBuilder(256)
+	builder.Finish(world.Pack(builder))
+	return builder.FinishedBytes()
+}
+
+func TestWorldFromBytesRejectsInvalidData(t *testing.T) {
+	var chain *Game.ImpulseT
+	for range 300 {
+		chain = &Game.ImpulseT{Direction: &Game.VectorT{X: 1}, Damping: 0.5, Next: chain}
+	}
+	cases := map[string][]byte{
+		"empty":     nil,
+		"short":     {1, 0},
+		"root out":  {0xff, 0xff, 0, 0, 0, 0, 0, 0},
+		"duplicate": packWorld(&Game.WorldT{Objects: []*Game.ObjectT{{ID: 1}, {ID: 1}}}),
+		"impulses":  packWorld(&Game.WorldT{Objects: []*Game.ObjectT{{ID: 1, Impulses: chain}}}),
+		"non-finite": packWorld(&Game.WorldT{Objects: []*Game.ObjectT{
+			{ID: 1, Position: &Game.VectorT{X: math.NaN()}},
+		}}),
+		"unknown type": packWorld(&Game.WorldT{Objects: []*Game.ObjectT{{ID: 1, Type: Game.ObjectType(42)}}}),
+	}
+	for name, data := range cases {
+		if world, err := engine.WorldFromBytes(data); !errors.Is(err, engine.ErrInvalidWorld) || world != nil {
+			t.Errorf("%s: expected ErrInvalidWorld, got %v %v", name, world, err)
+		}
+	}
+
+	// Every truncation of a valid world is rejected without panicking
+	data := newCrowdedWorld(20, 100).ToBytes()
+	for n := 0; n < len(data); n++ {
+		if _, err := engine.WorldFromBytes(data[:n]); err == nil {
+			t.Fatalf("Expected the world truncated to %d of %d bytes to be rejected", n, len(data))
+		}
+	}
+}
+
+func TestWorldFromBytesAcceptsMissingFields(t *testing.T) {
+	world, err := engine.WorldFromBytes(packWorld(&Game.WorldT{Gravity: 9.8, Objects: []*Game.ObjectT{{ID: 3}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if world.Boundary != (engine.Vector{}) || world.Objects[3] == nil || world.Objects[3].Size != (engine.Vector{}) {
+		t.Errorf("Expected zero vectors for the missing fields, got %+v", world)
+	}
+}
+
+func FuzzWorldFromBytes(f *testing.F) {
+	f.Add([]byte{})
+	f.Add(newCrowdedWorld(5, 100).ToBytes())
+	f.Add(newTestWorld(&engine.Object{
+		ID: 1, Type: engine.Creature, Size: engine.Vector{X: 1, Y: 1},
+		Impulses: &engine.Impulse{Direction: engine.Vector{X: 1}, Next: &engine.Impulse{Damping: 1}},
+	}).ToBytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		world, err := engine.WorldFromBytes(data)
+		if err != nil {
+			if !(errors.Is(err, engine.ErrInvalidWorld) || errors.Is(err, engine.ErrIncompatibleFormat)) || world != nil {
+				t.Fatalf("Expected a nil world and ErrInvalidWorld or ErrIncompatibleFormat, got %v %v", world, err)
+			}
+			return
+		}
+		// An accepted world survives a round trip
+		again, err := engine.WorldFromBytes(world.ToBytes())
+		if err != nil {
+			t.Fatalf("Expected the accepted world to round trip, got %v", err)
+		}
+		if len(again.Objects) != len(world.Objects) {
+			t.Fatalf("Expected %d objects after the round trip, got %d", len(world.Objects), len(again.Objects))
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Version of the engine written into the world header
+const EngineVersion = "0.1.0"
+
+// Format version of the serialized world written by this engine
+// Version 1 had no header, version 2 added WorldHeader to the World table
+// and the WorldDelta table, which always carries the header
+const FormatVersion = 2
+
+// Oldest format version able to read the data written by this engine,
+// raised only by the changes older readers would misread,
+// new fields appended to the tables are skipped by older readers
+// Raised to 2 with the floor moved to the bottom of the object's box (Position.Y == Size.Y/2):
+// engines of the format 1 rest the objects at Position.Y == 0 and would sink every resting object into the floor
+// Data of the format 1 is still read, its creatures, items and projectiles at Position.Y == 0 are lifted onto the floor by the next update
+const MinReaderFormatVersion = 2
+
+// ErrIncompatibleFormat is returned when the world data requires a newer format version
+var ErrIncompatibleFormat = errors.New("engine: incompatible world format")
+
+// WorldHeader describes the serialized world
+type WorldHeader struct {
+	FormatVersion    uint32  // Format version of the writer
+	MinReaderVersion uint32  // Oldest format version able to read the data
+	EngineVersion    string  // Engine version of the writer
+	Tick             uint64  // Server tick number
+	Timestamp        float64 // Server time in seconds
+}
+
+// Read the header of the serialized world without decoding the objects,
+// e.g. to check the compatibility or to order the snapshots
+// Data of the format version 1 has no header and reports FormatVersion 1
+func WorldHeaderFromBytes(data []byte) (WorldHeader, error) {
+	if err := _verifyWorld(data); err != nil {
+		return WorldHeader{}, err
+	}
+	return deserializeWorldHeader(data), nil
+}
+
+// Read the header of the serialized delta without applying it
+func WorldDeltaHeaderFromBytes(data []byte) (WorldHeader, error) {
+	if err := _verifyWorldDelta(data); err != nil {
+		return WorldHeader{}, err
+	}
+	return deserializeWorldDeltaHeader(data), nil
+}
+
+// Check if the data written with the header can be read by this engine:
+// older formats are always readable, newer ones only if the writer allows it
+// Used on the headers of the received data and the versions advertised by the server
+func (header WorldHeader) Compatible() error {
+	if header.FormatVersion == 0 || header.MinReaderVersion > header.FormatVersion {
+		return fmt.Errorf("%w: malformed header %d/%d", ErrInvalidWorld, header.FormatVersion, header.MinReaderVersion)
+	}
+	if header.MinReaderVersion > FormatVersion {
+		return fmt.Errorf("%w: data requires format %d, engine %s reads up to %d",
+			ErrIncompatibleFormat, header.MinReaderVersion, EngineVersion, FormatVersion)
+	}
+	return nil
+}
+
+// Check if a peer reading the format version can read the data written by this engine,
+// e.g. the version a client advertises when it connects
+// Returns ErrIncompatibleFormat if the peer is too old
+func CheckReaderVersion(version uint32) error {
+	if version < MinReaderFormatVersion {
+		return fmt.Errorf("%w: reader of format %d can't read format %d of engine %s",
+			ErrIncompatibleFormat, version, FormatVersion, EngineVersion)
+	}
+	return nil
+}
+
+// Serialize the world with the tick number and the server time in the header
+func (world *World) ToBytesAt(tick uint64, timestamp float64) []byte {
+	return serializeWorldToBytes(world, _currentHeader(tick, timestamp))
+}
+
+// Serialize the current world with the current tick and simulation time (see GetTime) in the header
+// Returns nil if there is no world
+// The world is serialized from a snapshot, so the update isn't blocked by the encoding
+func (engine *Engine) GetWorldBytes() []byte {
+	world, tick, timestamp := engine.snapshot()
+	if world == nil {
+		return nil
+	}
+	return serializeWorldToBytes(world, _currentHeader(tick, timestamp))
+}
+
+// -- Internal methods -- //
+
+// Header written by this engine
+func _currentHeader(tick uint64, timestamp float64) WorldHeader {
+	return WorldHeader{
+		FormatVersion:    FormatVersion,
+		MinReaderVersion: MinReaderFormatVersion,
+		EngineVersion:    EngineVersion,
+		Tick:             tick,
+		Timestamp:        timestamp,
+	}
+}
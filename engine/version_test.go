@@ -0,0 +1,191 @@
+package engine_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/plugfox/slash-engine-go/engine"
+	"github.com/plugfox/slash-engine-go/generated/Game"
+)
+
+// World written by the format version 1 (without the header), one creature with an impulse
+const worldV1Hex = "1000000000000a0020001800080004000a0000001c0000000000000000408f40" +
+	"0000000000407f409a9999999999234001000000200000001c0060005c005800" +
+	"00004400340024001400000010000000000004001c0000000000000000000040" +
+	"0000000058000000000000000000000000000000000000000000000000005940" +
+	"0000000000003440000000000000144000000000000000000000000000003440" +
+	"000000000000444000000000010000000700000008001c000c00040008000000" +
+	"000000000000e03f00000000000000000000000000c07240"
+
+// World written by the baseline schema (format version 1 without Restitution, Friction and Drag),
+// one creature with an impulse
+const worldBaselineHex = "1000000000000a0020001800080004000a0000001c0000000000000000408f40" +
+	"0000000000407f409a99999999992340010000001c000000000016005c005800" +
+	"540000004000300020001000080004001600000060000000000000000000f03f" +
+	"000000000000000000000000000034c000000000000059400000000000003440" +
+	"0000000000001440000000000000000000000000000034400000000000004440" +
+	"00000000010000000700000008001c000c00040008000000000000000000e03f" +
+	"00000000000000000000000000c07240"
+
+func TestWorldFromBytesReadsBaselineSchema(t *testing.T) {
+	data, err := hex.DecodeString(worldBaselineHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	world, err := engine.WorldFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := world.Objects[7]
+	if obj == nil {
+		t.Fatal("Expected the object 7")
+	}
+	expected := engine.Object{
+		ID: 7, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Velocity: engine.Vector{X: 5},
+		Position: engine.Vector{X: 100, Y: 20}, Anchor: engine.Vector{Y: -20}, GravityFactor: 1, Impulses: obj.Impulses,
+	}
+	if *obj != expected {
+		t.Errorf("Expected %+v with the new fields zeroed, got %+v", expected, *obj)
+	}
+	if obj.Impulses == nil || obj.Impulses.Direction != (engine.Vector{Y: 300}) || obj.Impulses.Damping != 0.5 || obj.Impulses.Next != nil {
+		t.Errorf("Expected the impulse to be decoded, got %+v", obj.Impulses)
+	}
+}
+
+func TestWorldFromBytesReadsFormatV1(t *testing.T) {
+	data, err := hex.DecodeString(worldV1Hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := engine.WorldHeaderFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.FormatVersion != 1 || header.MinReaderVersion != 1 || header.Tick != 0 {
+		t.Errorf("Expected the format version 1 without a tick, got %+v", header)
+	}
+
+	world, err := engine.WorldFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if world.Gravity != 9.8 || world.Boundary != (engine.Vector{X: 1000, Y: 500}) {
+		t.Errorf("Expected gravity 9.8 and boundary 1000x500, got %v %v", world.Gravity, world.Boundary)
+	}
+	obj := world.Objects[7]
+	if obj == nil {
+		t.Fatal("Expected the object 7")
+	}
+	if obj.Type != engine.Creature || obj.Position != (engine.Vector{X: 100, Y: 20}) ||
+		obj.Velocity != (engine.Vector{X: 5}) || obj.Friction != 2 {
+		t.Errorf("Unexpected object %+v", obj)
+	}
+	if obj.Impulses == nil || obj.Impulses.Direction != (engine.Vector{Y: 300}) || obj.Impulses.Damping != 0.5 {
+		t.Errorf("Expected the impulse to be decoded, got %+v", obj.Impulses)
+	}
+
+	// Written again the world gets the current header
+	header, err = engine.WorldHeaderFromBytes(world.ToBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.FormatVersion != engine.FormatVersion || header.EngineVersion != engine.EngineVersion {
+		t.Errorf("Expected the current format and engine versions, got %+v", header)
+	}
+}
+
+func TestGetWorldBytesHeader(t *testing.T) {
+	eng := newCommandEngine()
+	for range 3 {
+		eng.Step(0.5)
+	}
+	header, err := engine.WorldHeaderFromBytes(eng.GetWorldBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Tick != 3 || header.Timestamp != 1.5 || header.EngineVersion != engine.EngineVersion {
+		t.Errorf("Expected tick 3 at 1.5s, got %+v", header)
+	}
+	if (&engine.Engine{}).GetWorldBytes() != nil {
+		t.Error("Expected nil bytes without a world")
+	}
+}
+
+func TestWorldFromBytesFormatCompatibility(t *testing.T) {
+	objects := []*Game.ObjectT{{ID: 1, Type: Game.ObjectTypeCreature}}
+
+	// A newer writer still readable by this engine
+	newer := packWorld(&Game.WorldT{Objects: objects, Header: &Game.WorldHeaderT{
+		FormatVersion: engine.FormatVersion + 3, MinReaderVersion: 1, EngineVersion: "9.0.0", Tick: 42,
+	}})
+	if world, err := engine.WorldFromBytes(newer); err != nil || world.Objects[1] == nil {
+		t.Errorf("Expected the newer compatible world to be read, got %v %v", world, err)
+	}
+
+	// A newer writer requiring a newer reader
+	incompatible := packWorld(&Game.WorldT{Objects: objects, Header: &Game.WorldHeaderT{
+		FormatVersion: engine.FormatVersion + 1, MinReaderVersion: engine.FormatVersion + 1,
+	}})
+	if world, err := engine.WorldFromBytes(incompatible); !errors.Is(err, engine.ErrIncompatibleFormat) || world != nil {
+		t.Errorf("Expected ErrIncompatibleFormat, got %v %v", world, err)
+	}
+	if header, err := engine.WorldHeaderFromBytes(incompatible); err != nil || header.Compatible() == nil {
+		t.Errorf("Expected the header to be readable but incompatible, got %+v %v", header, err)
+	}
+
+	// Malformed headers
+	for _, header := range []*Game.WorldHeaderT{
+		{FormatVersion: 0},
+		{FormatVersion: 2, MinReaderVersion: 3},
+	} {
+		data := packWorld(&Game.WorldT{Objects: objects, Header: header})
+		if _, err := engine.WorldFromBytes(data); !errors.Is(err, engine.ErrInvalidWorld) {
+			t.Errorf("Expected ErrInvalidWorld for the header %+v, got %v", header, err)
+		}
+	}
+}
+
+func TestWorldFromDeltaFormatCompatibility(t *testing.T) {
+	baseline := newTestWorld(&engine.Object{ID: 1, Type: engine.Creature, Size: engine.Vector{X: 1, Y: 1}})
+
+	header, err := engine.WorldDeltaHeaderFromBytes(baseline.DeltaToBytes(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.FormatVersion != engine.FormatVersion || header.EngineVersion != engine.EngineVersion {
+		t.Errorf("Expected the current format and engine versions, got %+v", header)
+	}
+
+	// Deltas always carry the header
+	added := []*Game.ObjectT{{ID: 2, Type: Game.ObjectTypeItem}}
+	headerless := packDelta(&Game.WorldDeltaT{Added: added})
+	if _, err := engine.WorldDeltaHeaderFromBytes(headerless); !errors.Is(err, engine.ErrInvalidWorld) {
+		t.Errorf("Expected ErrInvalidWorld for a delta without a header, got %v", err)
+	}
+	if world, err := engine.WorldFromDelta(baseline, headerless); !errors.Is(err, engine.ErrInvalidWorld) || world != nil {
+		t.Errorf("Expected the delta without a header to be rejected, got %v %v", world, err)
+	}
+
+	incompatible := packDelta(&Game.WorldDeltaT{Added: added, Header: &Game.WorldHeaderT{
+		FormatVersion: engine.FormatVersion + 1, MinReaderVersion: engine.FormatVersion + 1,
+	}})
+	if world, err := engine.WorldFromDelta(baseline, incompatible); !errors.Is(err, engine.ErrIncompatibleFormat) || world != nil {
+		t.Errorf("Expected ErrIncompatibleFormat, got %v %v", world, err)
+	}
+}
+
+func TestCheckReaderVersion(t *testing.T) {
+	for _, version := range []uint32{engine.FormatVersion, engine.FormatVersion + 1} {
+		if err := engine.CheckReaderVersion(version); err != nil {
+			t.Errorf("Expected the reader of format %d to be compatible, got %v", version, err)
+		}
+	}
+	// Readers of the format 1 rest the objects at Position.Y == 0
+	for _, version := range []uint32{0, 1} {
+		if err := engine.CheckReaderVersion(version); !errors.Is(err, engine.ErrIncompatibleFormat) {
+			t.Errorf("Expected ErrIncompatibleFormat for format %d, got %v", version, err)
+		}
+	}
+}
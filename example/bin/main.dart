@@ -55,6 +55,10 @@ final class _ObjectStruct extends ffi.Struct {
         anchor: _VectorStruct.convert(obj.Anchor),
         gravityFactor: obj.GravityFactor,
         impulses: _ImpulseStruct.convert(obj.Impulses.ref),
+        bullet: obj.Bullet != 0,
+        restitution: obj.Restitution,
+        friction: obj.Friction,
+        drag: obj.Drag,
       );
 
   @ffi.Int32()
@@ -75,6 +79,18 @@ final class _ObjectStruct extends ffi.Struct {
   external double GravityFactor;
 
   external ffi.Pointer<_ImpulseStruct> Impulses;
+
+  @ffi.Uint8()
+  external int Bullet;
+
+  @ffi.Double()
+  external double Restitution;
+
+  @ffi.Double()
+  external double Friction;
+
+  @ffi.Double()
+  external double Drag;
 }
 
 /// World struct (corresponds to Go's World)
@@ -106,80 +122,211 @@ final class _WorldStruct extends ffi.Struct {
   external int ObjectCount;
 }
 
+// EngineCreate function
+typedef _EngineCreateC = ffi.Int32 Function();
+typedef _EngineCreateDart = int Function();
+
+// EngineDestroy function
+typedef _EngineDestroyC = ffi.Void Function(ffi.Int32 handle);
+typedef _EngineDestroyDart = void Function(int handle);
+
 // CreateWorld function
 typedef _CreateWorldC = ffi.Void Function(
+  ffi.Int32 handle,
   ffi.Double gravity,
-  ffi.Pointer<_VectorStruct> boundary,
+  _VectorStruct boundary,
 );
 typedef _CreateWorldDart = void Function(
+  int handle,
   double gravity,
-  ffi.Pointer<_VectorStruct> boundary,
+  _VectorStruct boundary,
 );
 
 // SetWorld function
 typedef _SetWorldC = ffi.Void Function(
+  ffi.Int32 handle,
   ffi.Pointer<_WorldStruct> world,
   ffi.Double rtt,
 );
 typedef _SetWorldDart = void Function(
+  int handle,
   ffi.Pointer<_WorldStruct> world,
   double rtt,
 );
 
 // Run function
-typedef _RunC = ffi.Void Function(ffi.Double tickMS);
-typedef _RunDart = void Function(double tickMS);
+typedef _RunC = ffi.Int32 Function(
+  ffi.Int32 handle,
+  ffi.Double tickMS,
+);
+typedef _RunDart = int Function(
+  int handle,
+  double tickMS,
+);
+
+// GetRunStatus function
+typedef _GetRunStatusC = ffi.Int32 Function(
+  ffi.Int32 handle,
+  ffi.Pointer<ffi.Char> message,
+  ffi.Int32 capacity,
+);
+typedef _GetRunStatusDart = int Function(
+  int handle,
+  ffi.Pointer<ffi.Char> message,
+  int capacity,
+);
 
 // Stop function
-typedef _StopC = ffi.Void Function();
-typedef _StopDart = void Function();
+typedef _StopC = ffi.Void Function(ffi.Int32 handle);
+typedef _StopDart = void Function(int handle);
+
+// Step function
+typedef _StepC = ffi.Uint64 Function(
+  ffi.Int32 handle,
+  ffi.Double dt,
+);
+typedef _StepDart = int Function(
+  int handle,
+  double dt,
+);
+
+// GetTick function
+typedef _GetTickC = ffi.Uint64 Function(ffi.Int32 handle);
+typedef _GetTickDart = int Function(int handle);
+
+// SetFixedStep function
+typedef _SetFixedStepC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Double stepMS,
+  ffi.Int32 maxSubsteps,
+);
+typedef _SetFixedStepDart = void Function(
+  int handle,
+  double stepMS,
+  int maxSubsteps,
+);
+
+// GetInterpolationAlpha function
+typedef _GetInterpolationAlphaC = ffi.Double Function(ffi.Int32 handle);
+typedef _GetInterpolationAlphaDart = double Function(int handle);
 
 // GetWorldPtr function
-typedef _GetWorldPtrC = ffi.Pointer<_WorldStruct> Function();
-typedef _GetWorldPtrDart = ffi.Pointer<_WorldStruct> Function();
+typedef _GetWorldPtrC = ffi.Pointer<_WorldStruct> Function(ffi.Int32 handle);
+typedef _GetWorldPtrDart = ffi.Pointer<_WorldStruct> Function(int handle);
 
 // Get world bytes function
 typedef _GetWorldBytesC = ffi.Pointer<ffi.Uint8> Function(
-    ffi.Pointer<ffi.Int32>);
+  ffi.Int32 handle,
+  ffi.Pointer<ffi.Int32>,
+);
 typedef _GetWorldBytesDart = ffi.Pointer<ffi.Uint8> Function(
-    ffi.Pointer<ffi.Int32>);
+  int handle,
+  ffi.Pointer<ffi.Int32>,
+);
 
 // _GetObjectPtr function
-typedef _GetObjectPtrC = ffi.Pointer<_ObjectStruct> Function(ffi.Int32 id);
-typedef _GetObjectPtrDart = ffi.Pointer<_ObjectStruct> Function(int id);
+typedef _GetObjectPtrC = ffi.Pointer<_ObjectStruct> Function(
+  ffi.Int32 handle,
+  ffi.Int32 id,
+);
+typedef _GetObjectPtrDart = ffi.Pointer<_ObjectStruct> Function(
+  int handle,
+  int id,
+);
 
 // UpsertObject function
-typedef _UpsertObjectC = ffi.Void Function(ffi.Pointer<_ObjectStruct>);
-typedef _UpsertObjectDart = void Function(ffi.Pointer<_ObjectStruct>);
+typedef _UpsertObjectC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Pointer<_ObjectStruct>,
+);
+typedef _UpsertObjectDart = void Function(
+  int handle,
+  ffi.Pointer<_ObjectStruct>,
+);
 
 // UpsertObjects function
 typedef _UpsertObjectsC = ffi.Void Function(
-    ffi.Pointer<_ObjectStruct>, ffi.Int32);
-typedef _UpsertObjectsDart = void Function(ffi.Pointer<_ObjectStruct>, int);
+  ffi.Int32 handle,
+  ffi.Pointer<_ObjectStruct>,
+  ffi.Int32,
+);
+typedef _UpsertObjectsDart = void Function(
+  int handle,
+  ffi.Pointer<_ObjectStruct>,
+  int,
+);
 
 // AddImpulse function
-typedef _AddImpulseC = ffi.Void Function(ffi.Int32, _VectorStruct, ffi.Double);
-typedef _AddImpulseDart = void Function(int, _VectorStruct, double);
+typedef _AddImpulseC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Int32,
+  _VectorStruct,
+  ffi.Double,
+);
+typedef _AddImpulseDart = void Function(
+  int handle,
+  int,
+  _VectorStruct,
+  double,
+);
 
 // SetVelocity function
-typedef _SetVelocityC = ffi.Void Function(ffi.Int32, _VectorStruct);
-typedef _SetVelocityDart = void Function(int, _VectorStruct);
+typedef _SetVelocityC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Int32,
+  _VectorStruct,
+);
+typedef _SetVelocityDart = void Function(
+  int handle,
+  int,
+  _VectorStruct,
+);
 
 // SetPosition function
-typedef _SetPositionC = ffi.Void Function(ffi.Int32, _VectorStruct);
-typedef _SetPositionDart = void Function(int, _VectorStruct);
+typedef _SetPositionC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Int32,
+  _VectorStruct,
+);
+typedef _SetPositionDart = void Function(
+  int handle,
+  int,
+  _VectorStruct,
+);
 
 // SetAnchor function
-typedef _SetAnchorC = ffi.Void Function(ffi.Int32, _VectorStruct);
-typedef _SetAnchorDart = void Function(int, _VectorStruct);
+typedef _SetAnchorC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Int32,
+  _VectorStruct,
+);
+typedef _SetAnchorDart = void Function(
+  int handle,
+  int,
+  _VectorStruct,
+);
 
 // RemoveObject function
-typedef _RemoveObjectC = ffi.Void Function(ffi.Int32);
-typedef _RemoveObjectDart = void Function(int);
+typedef _RemoveObjectC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Int32,
+);
+typedef _RemoveObjectDart = void Function(
+  int handle,
+  int,
+);
 
 // RemoveObjects function
-typedef _RemoveObjectsC = ffi.Void Function(ffi.Pointer<ffi.Int32>, ffi.Int32);
-typedef _RemoveObjectsDart = void Function(ffi.Pointer<ffi.Int32>, int);
+typedef _RemoveObjectsC = ffi.Void Function(
+  ffi.Int32 handle,
+  ffi.Pointer<ffi.Int32>,
+  ffi.Int32,
+);
+typedef _RemoveObjectsDart = void Function(
+  int handle,
+  ffi.Pointer<ffi.Int32>,
+  int,
+);
 
 // Utility function to open the shared library
 ffi.DynamicLibrary _openEngineLib() {
@@ -203,16 +350,30 @@ ffi.DynamicLibrary _openEngineLib() {
   throw UnsupportedError('Unknown platform: ${io.Platform.operatingSystem}');
 }
 
-class SlashEngine {
-  factory SlashEngine() => _instance ??= SlashEngine._(_openEngineLib());
-  static SlashEngine? _instance;
-  SlashEngine._(ffi.DynamicLibrary lib)
-      : _createWorldDart =
+/// Functions of the engine library, shared by all engine instances
+final class _SlashEngineBindings {
+  _SlashEngineBindings(ffi.DynamicLibrary lib)
+      : _engineCreateDart = lib
+            .lookupFunction<_EngineCreateC, _EngineCreateDart>('EngineCreate'),
+        _engineDestroyDart =
+            lib.lookupFunction<_EngineDestroyC, _EngineDestroyDart>(
+                'EngineDestroy'),
+        _createWorldDart =
             lib.lookupFunction<_CreateWorldC, _CreateWorldDart>('CreateWorld'),
         _setWorldDart =
             lib.lookupFunction<_SetWorldC, _SetWorldDart>('SetWorld'),
         _runDart = lib.lookupFunction<_RunC, _RunDart>('Run'),
+        _getRunStatusDart =
+            lib.lookupFunction<_GetRunStatusC, _GetRunStatusDart>(
+                'GetRunStatus'),
         _stopDart = lib.lookupFunction<_StopC, _StopDart>('Stop'),
+        _stepDart = lib.lookupFunction<_StepC, _StepDart>('Step'),
+        _getTickDart = lib.lookupFunction<_GetTickC, _GetTickDart>('GetTick'),
+        _setFixedStepDart = lib
+            .lookupFunction<_SetFixedStepC, _SetFixedStepDart>('SetFixedStep'),
+        _getInterpolationAlphaDart = lib.lookupFunction<
+            _GetInterpolationAlphaC,
+            _GetInterpolationAlphaDart>('GetInterpolationAlpha'),
         _getWorldPtrDart =
             lib.lookupFunction<_GetWorldPtrC, _GetWorldPtrDart>('GetWorldPtr'),
         _getWorldBytesDart =
@@ -239,9 +400,16 @@ class SlashEngine {
             lib.lookupFunction<_RemoveObjectsC, _RemoveObjectsDart>(
                 'RemoveObjects');
 
+  final _EngineCreateDart _engineCreateDart;
+  final _EngineDestroyDart _engineDestroyDart;
   final _CreateWorldDart _createWorldDart;
   final _SetWorldDart _setWorldDart;
   final _RunDart _runDart;
+  final _GetRunStatusDart _getRunStatusDart;
+  final _StepDart _stepDart;
+  final _GetTickDart _getTickDart;
+  final _SetFixedStepDart _setFixedStepDart;
+  final _GetInterpolationAlphaDart _getInterpolationAlphaDart;
   final _GetWorldPtrDart _getWorldPtrDart;
   final _GetWorldBytesDart _getWorldBytesDart;
   final _GetObjectPtrDart _getObjectPtrDart;
@@ -254,6 +422,32 @@ class SlashEngine {
   final _RemoveObjectDart _removeObjectDart;
   final _RemoveObjectsDart _removeObjectsDart;
   final _StopDart _stopDart;
+}
+
+/// Status of the update loop, mirrors the RunStatus enum of the C API
+abstract final class RunStatus {
+  static const ok = 0;
+  static const running = 1;
+  static const invalidTick = 2;
+  static const noWorld = 3;
+  static const alreadyRunning = 4;
+  static const failed = 5;
+}
+
+/// Engine instance, every instance has its own world and update loop
+class SlashEngine {
+  /// Create a new engine instance
+  /// Call [dispose] to destroy the instance when it is no longer needed
+  factory SlashEngine() {
+    final bindings = _bindings ??= _SlashEngineBindings(_openEngineLib());
+    return SlashEngine._(bindings, bindings._engineCreateDart());
+  }
+
+  SlashEngine._(this._lib, this._handle);
+
+  static _SlashEngineBindings? _bindings;
+  final _SlashEngineBindings _lib;
+  final int _handle;
 
   /// Create a new world with the given gravity, boundary
   void createWorld({
@@ -266,7 +460,7 @@ class SlashEngine {
       boundary.ref
         ..X = x
         ..Y = y;
-      _createWorldDart(gravity, boundary);
+      _lib._createWorldDart(_handle, gravity, boundary.ref);
     } finally {
       ffi.calloc.free(boundary);
     }
@@ -274,17 +468,52 @@ class SlashEngine {
 
   /// Set the world with the given round-trip time
   void setWorld(ffi.Pointer<_WorldStruct> world, double rtt) {
-    _setWorldDart(world, rtt);
+    _lib._setWorldDart(_handle, world, rtt);
   }
 
   /// Run the engine with the given tick interval
+  /// Throws [StateError] if the update loop can't be started
   void run(double tickMS) {
-    _runDart(tickMS);
+    final status = _lib._runDart(_handle, tickMS);
+    if (status != RunStatus.ok) {
+      throw StateError('Engine run failed with status $status');
+    }
   }
 
+  /// Get the status of the update loop, see [RunStatus]
+  int getRunStatus() => _lib._getRunStatusDart(_handle, ffi.nullptr, 0);
+
+  /// Get the reason the update loop stopped by itself, e.g. a panic,
+  /// null while it's running or after [stop]
+  String? getRunError() {
+    const capacity = 4096;
+    final message = ffi.calloc<ffi.Char>(capacity);
+    try {
+      final status = _lib._getRunStatusDart(_handle, message, capacity);
+      if (status != RunStatus.failed) return null;
+      return message.cast<ffi.Utf8>().toDartString();
+    } finally {
+      ffi.calloc.free(message);
+    }
+  }
+
+  /// Advance the world synchronously by [dt] seconds, returns the tick number
+  int step(double dt) => _lib._stepDart(_handle, dt);
+
+  /// Get the current tick number
+  int getTick() => _lib._getTickDart(_handle);
+
+  /// Enable the fixed-step mode, zero or negative step disables it
+  void setFixedStep(double stepMS, {int maxSubsteps = 8}) {
+    _lib._setFixedStepDart(_handle, stepMS, maxSubsteps);
+  }
+
+  /// Get the interpolation fraction between the previous and current state
+  double getInterpolationAlpha() => _lib._getInterpolationAlphaDart(_handle);
+
   /// Get the current world by reference
   GameWorld? getWorldPtr() {
-    final ptr = _getWorldPtrDart();
+    final ptr = _lib._getWorldPtrDart(_handle);
     if (ptr.address == 0) return null;
     return _WorldStruct.convert(ptr.ref);
   }
@@ -293,7 +522,7 @@ class SlashEngine {
   Uint8List? getWorldBytes() {
     // Запрашиваем размер данных
     final sizePtr = ffi.calloc<ffi.Int32>();
-    final dataPtr = _getWorldBytesDart(sizePtr);
+    final dataPtr = _lib._getWorldBytesDart(_handle, sizePtr);
 
     final size = sizePtr.value;
     ffi.calloc.free(sizePtr);
@@ -314,7 +543,7 @@ class SlashEngine {
 
   /// Get the current object
   GameObject? getObjectPtr(int id) {
-    final ptr = _getObjectPtrDart(id);
+    final ptr = _lib._getObjectPtrDart(_handle, id);
     if (ptr.address == 0) return null;
     return _ObjectStruct.convert(ptr.ref);
   }
@@ -335,8 +564,12 @@ class SlashEngine {
         ..Position.Y = object.position.y
         ..Anchor.X = object.anchor.x
         ..Anchor.Y = object.anchor.y
-        ..GravityFactor = object.gravityFactor;
-      _upsertObjectDart(ptr);
+        ..GravityFactor = object.gravityFactor
+        ..Bullet = object.bullet ? 1 : 0
+        ..Restitution = object.restitution
+        ..Friction = object.friction
+        ..Drag = object.drag;
+      _lib._upsertObjectDart(_handle, ptr);
     } finally {
       ffi.calloc.free(ptr);
     }
@@ -363,22 +596,31 @@ class SlashEngine {
           ..Position.Y = object.position.y
           ..Anchor.X = object.anchor.x
           ..Anchor.Y = object.anchor.y
-          ..GravityFactor = object.gravityFactor;
+          ..GravityFactor = object.gravityFactor
+          ..Bullet = object.bullet ? 1 : 0
+          ..Restitution = object.restitution
+          ..Friction = object.friction
+          ..Drag = object.drag;
       }
-      _upsertObjectsDart(ptr, count);
+      _lib._upsertObjectsDart(_handle, ptr, count);
     } finally {
       ffi.calloc.free(ptr);
     }
   }
 
   /// Add an impulse to an object
+  ///
+  /// The direction is an acceleration decaying as `damping^t` (t in seconds).
+  /// An immediate impulse (`damping <= 0.01`) adds the whole direction
+  /// to the velocity once, whatever the tick duration.
+  /// Older engines added `direction * elapsed`, see README.md.
   void addImpulse(int id, Vector direction, double damping) {
     final vector = ffi.calloc<_VectorStruct>();
     try {
       vector.ref
         ..X = direction.x
         ..Y = direction.y;
-      _addImpulseDart(id, vector.ref, damping);
+      _lib._addImpulseDart(_handle, id, vector.ref, damping);
     } finally {
       ffi.calloc.free(vector);
     }
@@ -391,7 +633,7 @@ class SlashEngine {
       vector.ref
         ..X = velocity.x
         ..Y = velocity.y;
-      _setVelocityDart(id, vector.ref);
+      _lib._setVelocityDart(_handle, id, vector.ref);
     } finally {
       ffi.calloc.free(vector);
     }
@@ -404,7 +646,7 @@ class SlashEngine {
       vector.ref
         ..X = position.x
         ..Y = position.y;
-      _setPositionDart(id, vector.ref);
+      _lib._setPositionDart(_handle, id, vector.ref);
     } finally {
       ffi.calloc.free(vector);
     }
@@ -417,7 +659,7 @@ class SlashEngine {
       vector.ref
         ..X = anchor.x
         ..Y = anchor.y;
-      _setAnchorDart(id, vector.ref);
+      _lib._setAnchorDart(_handle, id, vector.ref);
     } finally {
       ffi.calloc.free(vector);
     }
@@ -425,7 +667,7 @@ class SlashEngine {
 
   /// Remove a single object
   void removeObject(int id) {
-    _removeObjectDart(id);
+    _lib._removeObjectDart(_handle, id);
   }
 
   /// Remove multiple objects
@@ -438,7 +680,7 @@ class SlashEngine {
         ptr[i] = id;
         i++;
       }
-      _removeObjectsDart(ptr, count);
+      _lib._removeObjectsDart(_handle, ptr, count);
     } finally {
       ffi.calloc.free(ptr);
     }
@@ -446,7 +688,12 @@ class SlashEngine {
 
   /// Stop the engine
   void stop() {
-    _stopDart();
+    _lib._stopDart(_handle);
+  }
+
+  /// Destroy the engine instance, the instance can't be used afterwards
+  void dispose() {
+    _lib._engineDestroyDart(_handle);
   }
 }
 
@@ -459,6 +706,8 @@ void main() async {
     y: 500.0,
   );
 
+  // Position is the center of the object, so a 64 units tall creature
+  // stands on the floor at y = 32 and its bottom center (anchor) is 32 units below
   engine.upsertObjectsPtr([
     GameObject(
       id: 1,
@@ -466,8 +715,8 @@ void main() async {
       client: true,
       size: Vector(24.0, 64.0),
       velocity: Vector(0.0, 0.0),
-      position: Vector(100.0, 100.0),
-      anchor: Vector(0.0, 0.0),
+      position: Vector(100.0, 32.0),
+      anchor: Vector(0.0, -32.0),
       gravityFactor: 1.0,
     ),
     GameObject(
@@ -476,8 +725,8 @@ void main() async {
       client: true,
       size: Vector(24.0, 64.0),
       velocity: Vector(0.0, 0.0),
-      position: Vector(132.0, 100.0),
-      anchor: Vector(0.0, 0.0),
+      position: Vector(132.0, 32.0),
+      anchor: Vector(0.0, -32.0),
       gravityFactor: 1.0,
     ),
     GameObject(
@@ -486,8 +735,8 @@ void main() async {
       client: true,
       size: Vector(24.0, 64.0),
       velocity: Vector(0.0, 0.0),
-      position: Vector(164.0, 100.0),
-      anchor: Vector(0.0, 0.0),
+      position: Vector(164.0, 32.0),
+      anchor: Vector(0.0, -32.0),
       gravityFactor: 1.0,
     ),
     GameObject(
@@ -496,14 +745,17 @@ void main() async {
       client: true,
       size: Vector(24.0, 64.0),
       velocity: Vector(0.0, 0.0),
-      position: Vector(196.0, 100.0),
-      anchor: Vector(0.0, 0.0),
+      position: Vector(196.0, 32.0),
+      anchor: Vector(0.0, -32.0),
       gravityFactor: 1.0,
     ),
   ]);
 
   var bytes = engine.getWorldBytes();
 
+  // Jump: the immediate impulse changes the vertical speed by 300 units per second
+  engine.addImpulse(1, Vector(0.0, 300.0), 0.0);
+
   // Run the engine
   engine.run(16.0);
 
@@ -513,7 +765,21 @@ void main() async {
 
   await Future.delayed(const Duration(seconds: 1));
 
+  // The update loop stops by itself if the update panics
+  final error = engine.getRunError();
+  if (error != null) print('Engine failed: $error');
+
   // Stop the engine
   engine.stop();
   print('Engine stopped');
+
+  // Independent engine instance, e.g. a server-authoritative world
+  final server = SlashEngine();
+  server.createWorld(gravity: 9.81, x: 1000.0, y: 500.0);
+  final tick = server.step(0.016);
+  print('Server world advanced to tick $tick');
+
+  // Destroy the engine instances
+  server.dispose();
+  engine.dispose();
 }
@@ -33,6 +33,10 @@ class GameObject {
   final Vector anchor;
   final double gravityFactor;
   final Impulse? impulses;
+  final bool bullet;
+  final double restitution;
+  final double friction;
+  final double drag;
 
   GameObject({
     required this.id,
@@ -44,13 +48,19 @@ class GameObject {
     required this.anchor,
     required this.gravityFactor,
     this.impulses,
+    this.bullet = false,
+    this.restitution = 0,
+    this.friction = 0,
+    this.drag = 0,
   });
 
   @override
   String toString() {
     return 'GameObject(id: $id, type: $type, client: $client, '
         'size: $size, velocity: $velocity, position: $position, '
-        'anchor: $anchor, gravityFactor: $gravityFactor, impulses: $impulses)';
+        'anchor: $anchor, gravityFactor: $gravityFactor, impulses: $impulses, '
+        'bullet: $bullet, restitution: $restitution, friction: $friction, '
+        'drag: $drag)';
   }
 }
 
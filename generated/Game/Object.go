@@ -16,6 +16,10 @@ type ObjectT struct {
 	Anchor *VectorT
 	GravityFactor float64
 	Impulses *ImpulseT
+	Bullet bool
+	Restitution float64
+	Friction float64
+	Drag float64
 }
 
 func (t *ObjectT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
@@ -35,6 +39,10 @@ func (t *ObjectT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	ObjectAddAnchor(builder, AnchorOffset)
 	ObjectAddGravityFactor(builder, t.GravityFactor)
 	ObjectAddImpulses(builder, ImpulsesOffset)
+	ObjectAddBullet(builder, t.Bullet)
+	ObjectAddRestitution(builder, t.Restitution)
+	ObjectAddFriction(builder, t.Friction)
+	ObjectAddDrag(builder, t.Drag)
 	return ObjectEnd(builder)
 }
 
@@ -48,6 +56,10 @@ func (rcv *Object) UnPackTo(t *ObjectT) {
 	t.Anchor = rcv.Anchor(nil).UnPack()
 	t.GravityFactor = rcv.GravityFactor()
 	t.Impulses = rcv.Impulses(nil).UnPack()
+	t.Bullet = rcv.Bullet()
+	t.Restitution = rcv.Restitution()
+	t.Friction = rcv.Friction()
+	t.Drag = rcv.Drag()
 }
 
 func (rcv *Object) UnPack() *ObjectT {
@@ -190,8 +202,56 @@ func (rcv *Object) Impulses(obj *Impulse) *Impulse {
 	return nil
 }
 
+func (rcv *Object) Bullet() bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(22))
+	if o != 0 {
+		return rcv._tab.GetBool(o + rcv._tab.Pos)
+	}
+	return false
+}
+
+func (rcv *Object) MutateBullet(n bool) bool {
+	return rcv._tab.MutateBoolSlot(22, n)
+}
+
+func (rcv *Object) Restitution() float64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(24))
+	if o != 0 {
+		return rcv._tab.GetFloat64(o + rcv._tab.Pos)
+	}
+	return 0.0
+}
+
+func (rcv *Object) MutateRestitution(n float64) bool {
+	return rcv._tab.MutateFloat64Slot(24, n)
+}
+
+func (rcv *Object) Friction() float64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(26))
+	if o != 0 {
+		return rcv._tab.GetFloat64(o + rcv._tab.Pos)
+	}
+	return 0.0
+}
+
+func (rcv *Object) MutateFriction(n float64) bool {
+	return rcv._tab.MutateFloat64Slot(26, n)
+}
+
+func (rcv *Object) Drag() float64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(28))
+	if o != 0 {
+		return rcv._tab.GetFloat64(o + rcv._tab.Pos)
+	}
+	return 0.0
+}
+
+func (rcv *Object) MutateDrag(n float64) bool {
+	return rcv._tab.MutateFloat64Slot(28, n)
+}
+
 func ObjectStart(builder *flatbuffers.Builder) {
-	builder.StartObject(9)
+	builder.StartObject(13)
 }
 func ObjectAddID(builder *flatbuffers.Builder, ID int32) {
 	builder.PrependInt32Slot(0, ID, 0)
@@ -220,6 +280,18 @@ func ObjectAddGravityFactor(builder *flatbuffers.Builder, GravityFactor float64)
 func ObjectAddImpulses(builder *flatbuffers.Builder, Impulses flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(8, flatbuffers.UOffsetT(Impulses), 0)
 }
+func ObjectAddBullet(builder *flatbuffers.Builder, Bullet bool) {
+	builder.PrependBoolSlot(9, Bullet, false)
+}
+func ObjectAddRestitution(builder *flatbuffers.Builder, Restitution float64) {
+	builder.PrependFloat64Slot(10, Restitution, 0.0)
+}
+func ObjectAddFriction(builder *flatbuffers.Builder, Friction float64) {
+	builder.PrependFloat64Slot(11, Friction, 0.0)
+}
+func ObjectAddDrag(builder *flatbuffers.Builder, Drag float64) {
+	builder.PrependFloat64Slot(12, Drag, 0.0)
+}
 func ObjectEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
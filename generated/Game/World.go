@@ -10,6 +10,7 @@ type WorldT struct {
 	Gravity float64
 	Boundary *VectorT
 	Objects []*ObjectT
+	Header *WorldHeaderT
 }
 
 func (t *WorldT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
@@ -27,11 +28,13 @@ func (t *WorldT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 		}
 		ObjectsOffset = builder.EndVector(ObjectsLength)
 	}
+	HeaderOffset := t.Header.Pack(builder)
 	WorldStart(builder)
 	WorldAddGravity(builder, t.Gravity)
 	BoundaryOffset := t.Boundary.Pack(builder)
 	WorldAddBoundary(builder, BoundaryOffset)
 	WorldAddObjects(builder, ObjectsOffset)
+	WorldAddHeader(builder, HeaderOffset)
 	return WorldEnd(builder)
 }
 
@@ -45,6 +48,7 @@ func (rcv *World) UnPackTo(t *WorldT) {
 		rcv.Objects(&x, j)
 		t.Objects[j] = x.UnPack()
 	}
+	t.Header = rcv.Header(nil).UnPack()
 }
 
 func (rcv *World) UnPack() *WorldT {
@@ -119,8 +123,21 @@ func (rcv *World) ObjectsLength() int {
 	return 0
 }
 
+func (rcv *World) Header(obj *WorldHeader) *WorldHeader {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(10))
+	if o != 0 {
+		x := rcv._tab.Indirect(o + rcv._tab.Pos)
+		if obj == nil {
+			obj = new(WorldHeader)
+		}
+		obj.Init(rcv._tab.Bytes, x)
+		return obj
+	}
+	return nil
+}
+
 func WorldStart(builder *flatbuffers.Builder) {
-	builder.StartObject(3)
+	builder.StartObject(4)
 }
 func WorldAddGravity(builder *flatbuffers.Builder, Gravity float64) {
 	builder.PrependFloat64Slot(0, Gravity, 0.0)
@@ -134,6 +151,9 @@ func WorldAddObjects(builder *flatbuffers.Builder, Objects flatbuffers.UOffsetT)
 func WorldStartObjectsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
 	return builder.StartVector(4, numElems, 4)
 }
+func WorldAddHeader(builder *flatbuffers.Builder, Header flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(3, flatbuffers.UOffsetT(Header), 0)
+}
 func WorldEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
@@ -0,0 +1,67 @@
+package server
+
+import "github.com/plugfox/slash-engine-go/engine"
+
+// Client is the client side of a server connection
+// It sends the predicted inputs and receives the world snapshots,
+// see Engine.PredictVelocity, Engine.PredictImpulse and Engine.Reconcile
+type Client struct {
+	conn Conn
+	id   uint32
+}
+
+// Create a client on the connection returned by a Dial function
+// Blocks until the server greets the client with its id,
+// then tells the server the world format version the client reads
+// Returns engine.ErrIncompatibleFormat if the client can't read the snapshots of the server
+func NewClient(conn Conn) (*Client, error) {
+	for {
+		data, err := conn.Receive()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		greeting, err := decodeWelcome(data)
+		if err != nil {
+			continue
+		}
+		if err := greeting.header.Compatible(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := conn.Send(encodeHello(engine.FormatVersion)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return &Client{conn: conn, id: greeting.clientID}, nil
+	}
+}
+
+// Get the id assigned to the client by the server
+func (client *Client) ID() uint32 {
+	return client.id
+}
+
+// Send the input to the server, the sequence number must grow with each input
+func (client *Client) SendInput(input engine.Input) error {
+	return client.conn.Send(encodeInput(input))
+}
+
+// Wait for the next world snapshot from the server
+func (client *Client) Receive() (Snapshot, error) {
+	for {
+		data, err := client.conn.Receive()
+		if err != nil {
+			return Snapshot{}, err
+		}
+		if len(data) == 0 || data[0] != messageSnapshot {
+			continue
+		}
+		return decodeSnapshot(data)
+	}
+}
+
+// Close the connection
+func (client *Client) Close() error {
+	return client.conn.Close()
+}
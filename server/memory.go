@@ -0,0 +1,108 @@
+package server
+
+import "sync"
+
+// Number of messages buffered in each direction of an in-memory connection
+const memoryQueueSize = 64
+
+// MemoryTransport connects clients in the same process without the network,
+// useful for tests and single player games running the server locally
+type MemoryTransport struct {
+	accept chan Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// In-memory connection, one side of a pair
+type memoryConn struct {
+	in     chan []byte   // Messages from the other side
+	out    chan []byte   // Messages to the other side
+	closed chan struct{} // Closed by this side
+	peer   chan struct{} // Closed by the other side
+	once   sync.Once
+}
+
+// Create a new in-memory transport
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{
+		accept: make(chan Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// Connect a new client, returns the client side of the connection
+// Blocks until the server accepts the connection
+func (transport *MemoryTransport) Dial() (Conn, error) {
+	toServer := make(chan []byte, memoryQueueSize)
+	toClient := make(chan []byte, memoryQueueSize)
+	clientClosed := make(chan struct{})
+	serverClosed := make(chan struct{})
+	client := &memoryConn{in: toClient, out: toServer, closed: clientClosed, peer: serverClosed}
+	server := &memoryConn{in: toServer, out: toClient, closed: serverClosed, peer: clientClosed}
+
+	select {
+	case transport.accept <- server:
+		return client, nil
+	case <-transport.closed:
+		return nil, ErrClosed
+	}
+}
+
+func (transport *MemoryTransport) Accept() (Conn, error) {
+	select {
+	case conn := <-transport.accept:
+		return conn, nil
+	case <-transport.closed:
+		return nil, ErrClosed
+	}
+}
+
+func (transport *MemoryTransport) Close() error {
+	transport.once.Do(func() { close(transport.closed) })
+	return nil
+}
+
+func (transport *MemoryTransport) Addr() string {
+	return "memory"
+}
+
+func (conn *memoryConn) Send(data []byte) error {
+	message := append([]byte(nil), data...) // The caller may reuse the buffer
+	select {
+	case <-conn.closed:
+		return ErrClosed
+	case <-conn.peer:
+		return ErrClosed
+	default:
+	}
+	select {
+	case conn.out <- message:
+		return nil
+	case <-conn.closed:
+		return ErrClosed
+	case <-conn.peer:
+		return ErrClosed
+	}
+}
+
+func (conn *memoryConn) Receive() ([]byte, error) {
+	select {
+	case message := <-conn.in:
+		return message, nil
+	case <-conn.closed:
+		return nil, ErrClosed
+	case <-conn.peer:
+		// Deliver the messages sent before the other side closed the connection
+		select {
+		case message := <-conn.in:
+			return message, nil
+		default:
+			return nil, ErrClosed
+		}
+	}
+}
+
+func (conn *memoryConn) Close() error {
+	conn.once.Do(func() { close(conn.closed) })
+	return nil
+}
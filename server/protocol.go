@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// ErrInvalidMessage is returned when a received message can't be decoded
+var ErrInvalidMessage = errors.New("server: invalid message")
+
+// Kind of a message, the first byte of every message
+const (
+	messageWelcome  byte = iota + 1 // Server -> client: client id and the world format versions
+	messageInput                    // Client -> server: input command
+	messageSnapshot                 // Server -> client: world snapshot
+	messageHello                    // Client -> server: world format version the client reads
+)
+
+// Message sizes in bytes, all numbers are little-endian
+const (
+	welcomeSize        = 1 + 4 + 4 + 4       // kind, client id, format version, min reader version
+	helloSize          = 1 + 4               // kind, format version
+	inputSize          = 1 + 4 + 4 + 1 + 8*3 // kind, sequence, object id, input kind, vector, damping
+	snapshotHeaderSize = 1 + 8 + 4           // kind, tick, last acknowledged sequence
+)
+
+// Snapshot is the authoritative world state broadcast by the server after each tick
+type Snapshot struct {
+	Tick    uint64        // Server tick number
+	LastAck uint32        // Last input sequence of the client processed by the server
+	World   *engine.World // World state after the tick
+}
+
+// Greeting of the client with the versions of the snapshots the server writes
+type welcome struct {
+	clientID uint32
+	header   engine.WorldHeader // Format and min reader versions
+}
+
+func encodeWelcome(clientID uint32) []byte {
+	data := make([]byte, welcomeSize)
+	data[0] = messageWelcome
+	binary.LittleEndian.PutUint32(data[1:], clientID)
+	binary.LittleEndian.PutUint32(data[5:], engine.FormatVersion)
+	binary.LittleEndian.PutUint32(data[9:], engine.MinReaderFormatVersion)
+	return data
+}
+
+func decodeWelcome(data []byte) (welcome, error) {
+	if len(data) != welcomeSize || data[0] != messageWelcome {
+		return welcome{}, ErrInvalidMessage
+	}
+	return welcome{
+		clientID: binary.LittleEndian.Uint32(data[1:]),
+		header: engine.WorldHeader{
+			FormatVersion:    binary.LittleEndian.Uint32(data[5:]),
+			MinReaderVersion: binary.LittleEndian.Uint32(data[9:]),
+		},
+	}, nil
+}
+
+func encodeHello(formatVersion uint32) []byte {
+	data := make([]byte, helloSize)
+	data[0] = messageHello
+	binary.LittleEndian.PutUint32(data[1:], formatVersion)
+	return data
+}
+
+func decodeHello(data []byte) (uint32, error) {
+	if len(data) != helloSize || data[0] != messageHello {
+		return 0, ErrInvalidMessage
+	}
+	return binary.LittleEndian.Uint32(data[1:]), nil
+}
+
+// Inputs use their own fixed-size format instead of the engine.Command batches:
+// the clients send only the predicted inputs Engine.Reconcile can replay (impulses and velocities),
+// they are authorized by Config.Authorize on the connection goroutine without the engine lock
+// and applied at the next tick, while Engine.ApplyCommands is for the hosts with their own transport
+func encodeInput(input engine.Input) []byte {
+	data := make([]byte, inputSize)
+	data[0] = messageInput
+	binary.LittleEndian.PutUint32(data[1:], input.Sequence)
+	binary.LittleEndian.PutUint32(data[5:], uint32(int32(input.ObjectID)))
+	data[9] = byte(input.Kind)
+	binary.LittleEndian.PutUint64(data[10:], math.Float64bits(input.Vector.X))
+	binary.LittleEndian.PutUint64(data[18:], math.Float64bits(input.Vector.Y))
+	binary.LittleEndian.PutUint64(data[26:], math.Float64bits(input.Damping))
+	return data
+}
+
+func decodeInput(data []byte) (engine.Input, error) {
+	if len(data) != inputSize || data[0] != messageInput {
+		return engine.Input{}, ErrInvalidMessage
+	}
+	input := engine.Input{
+		Sequence: binary.LittleEndian.Uint32(data[1:]),
+		ObjectID: int(int32(binary.LittleEndian.Uint32(data[5:]))),
+		Kind:     engine.InputKind(data[9]),
+		Vector: engine.Vector{
+			X: math.Float64frombits(binary.LittleEndian.Uint64(data[10:])),
+			Y: math.Float64frombits(binary.LittleEndian.Uint64(data[18:])),
+		},
+		Damping: math.Float64frombits(binary.LittleEndian.Uint64(data[26:])),
+	}
+	switch input.Kind {
+	case engine.InputImpulse, engine.InputVelocity:
+	default:
+		return engine.Input{}, fmt.Errorf("%w: unknown input kind %d", ErrInvalidMessage, input.Kind)
+	}
+	if !_isFinite(input.Vector.X) || !_isFinite(input.Vector.Y) || !_isFinite(input.Damping) {
+		return engine.Input{}, fmt.Errorf("%w: non-finite input", ErrInvalidMessage)
+	}
+	return input, nil
+}
+
+// Prepend the snapshot header to the world serialized with World.ToBytes
+func encodeSnapshot(tick uint64, lastAck uint32, world []byte) []byte {
+	data := make([]byte, snapshotHeaderSize+len(world))
+	data[0] = messageSnapshot
+	binary.LittleEndian.PutUint64(data[1:], tick)
+	binary.LittleEndian.PutUint32(data[9:], lastAck)
+	copy(data[snapshotHeaderSize:], world)
+	return data
+}
+
+func decodeSnapshot(data []byte) (Snapshot, error) {
+	if len(data) <= snapshotHeaderSize || data[0] != messageSnapshot {
+		return Snapshot{}, ErrInvalidMessage
+	}
+	world, err := engine.WorldFromBytes(data[snapshotHeaderSize:])
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+	return Snapshot{
+		Tick:    binary.LittleEndian.Uint64(data[1:]),
+		LastAck: binary.LittleEndian.Uint32(data[9:]),
+		World:   world,
+	}, nil
+}
+
+func _isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/plugfox/slash-engine-go/engine"
+)
+
+// Default number of simulation ticks per second
+const defaultTickRate = 30
+
+// Number of outgoing messages queued per client, the oldest snapshots are dropped for slow clients
+const outboxSize = 8
+
+// Number of inputs queued per client between two ticks, newer ones are dropped
+const maxPendingInputs = 64
+
+// Config of the server
+type Config struct {
+	// Simulation ticks per second, 30 by default
+	TickRate float64
+
+	// Check whether the client may apply the input, e.g. owns the object
+	// Nil rejects every input, so the clients only watch the world
+	Authorize func(clientID uint32, input engine.Input) bool
+
+	// Called from the connection goroutines when a client connects and disconnects
+	OnConnect    func(clientID uint32)
+	OnDisconnect func(clientID uint32)
+
+	// Called from the connection goroutines when a message is dropped without disconnecting the client,
+	// e.g. with ErrMessageTooLarge when the world doesn't fit into the transport,
+	// and with engine.ErrIncompatibleFormat before disconnecting a client too old to read the world
+	OnError func(clientID uint32, err error)
+}
+
+// Server is an authoritative game server owning the engine
+// It accepts clients from any number of transports, applies their inputs,
+// steps the world at a fixed rate and broadcasts the world snapshot to every client after each tick
+//
+// The server steps the engine itself, so don't call Engine.Run on it
+type Server struct {
+	engine *engine.Engine
+	config Config
+
+	mutex      sync.Mutex
+	clients    map[uint32]*client // Connected clients by id
+	lastID     uint32             // Id of the last connected client
+	pending    []clientInput      // Inputs received since the last tick
+	transports []Transport        // Transports being served
+	running    bool               // Tick loop is started
+	closed     bool               // Server is closed
+	stop       chan struct{}      // Closed to stop the tick loop
+	done       chan struct{}      // Closed when the tick loop returns
+
+	stepMutex sync.Mutex // Serializes the ticks
+}
+
+// Connected client
+type client struct {
+	id      uint32
+	conn    Conn
+	lastAck uint32        // Last processed input sequence, guarded by stepMutex
+	pending int           // Inputs queued since the last tick, guarded by mutex
+	outbox  chan []byte   // Messages to send
+	closed  chan struct{} // Closed on disconnect
+	once    sync.Once
+}
+
+// Input received from a client
+type clientInput struct {
+	client *client
+	input  engine.Input
+}
+
+// Create a server for the engine, the engine must have a world
+func New(eng *engine.Engine, config Config) *Server {
+	return &Server{
+		engine:  eng,
+		config:  config,
+		clients: make(map[uint32]*client),
+	}
+}
+
+// Get the engine owned by the server
+func (server *Server) Engine() *engine.Engine {
+	return server.engine
+}
+
+// Accept the clients from the transport until it or the server is closed
+// Blocks, so usually called in a goroutine, the transport is closed with the server
+func (server *Server) Serve(transport Transport) error {
+	server.mutex.Lock()
+	if server.closed {
+		server.mutex.Unlock()
+		transport.Close()
+		return ErrClosed
+	}
+	server.transports = append(server.transports, transport)
+	server.mutex.Unlock()
+
+	for {
+		conn, err := transport.Accept()
+		if err != nil {
+			if errors.Is(err, ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		server.connect(conn)
+	}
+}
+
+// Start stepping the world at the tick rate in the background
+func (server *Server) Start() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.running || server.closed {
+		return
+	}
+	server.running = true
+	server.stop = make(chan struct{})
+	server.done = make(chan struct{})
+	go server.loop(server.stop, server.done)
+}
+
+// Run a single tick synchronously: apply the received inputs, step the world
+// and broadcast the snapshot, can be used instead of Start for tests and custom loops
+// Returns the new tick number
+func (server *Server) Step() uint64 {
+	server.stepMutex.Lock()
+	defer server.stepMutex.Unlock()
+
+	server.mutex.Lock()
+	pending := server.pending
+	server.pending = nil
+	for _, received := range pending {
+		received.client.pending = 0
+	}
+	server.mutex.Unlock()
+
+	for _, received := range pending {
+		c, input := received.client, received.input
+		if input.Sequence <= c.lastAck {
+			continue // Duplicated or arrived after a newer one
+		}
+		c.lastAck = input.Sequence
+		switch input.Kind {
+		case engine.InputImpulse:
+			server.engine.AddImpulse(input.ObjectID, input.Vector, input.Damping)
+		case engine.InputVelocity:
+			server.engine.SetVelocity(input.ObjectID, input.Vector)
+		}
+	}
+
+	tick := server.engine.Step(1 / server.tickRate())
+	data := server.engine.GetWorldBytes()
+	if data == nil {
+		return tick
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	for _, c := range server.clients {
+		c.send(encodeSnapshot(tick, c.lastAck, data))
+	}
+	return tick
+}
+
+// Stop the tick loop, close the transports and disconnect the clients
+func (server *Server) Close() error {
+	server.mutex.Lock()
+	if server.closed {
+		server.mutex.Unlock()
+		return nil
+	}
+	server.closed = true
+	running, done := server.running, server.done
+	if running {
+		server.running = false
+		close(server.stop)
+	}
+	transports := server.transports
+	server.transports = nil
+	clients := make([]*client, 0, len(server.clients))
+	for _, c := range server.clients {
+		clients = append(clients, c)
+	}
+	server.mutex.Unlock()
+
+	if running {
+		<-done
+	}
+	var err error
+	for _, transport := range transports {
+		if closeErr := transport.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	for _, c := range clients {
+		server.disconnect(c)
+	}
+	return err
+}
+
+// -- Internal methods -- //
+
+// Step the world at the tick rate until stopped
+func (server *Server) loop(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / server.tickRate()))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			server.Step()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Register the connection, greet the client and start its goroutines
+func (server *Server) connect(conn Conn) {
+	server.mutex.Lock()
+	if server.closed {
+		server.mutex.Unlock()
+		conn.Close()
+		return
+	}
+	server.lastID++
+	c := &client{id: server.lastID, conn: conn, outbox: make(chan []byte, outboxSize), closed: make(chan struct{})}
+	server.mutex.Unlock()
+
+	// The welcome message goes before any snapshot
+	if err := conn.Send(encodeWelcome(c.id)); err != nil {
+		conn.Close()
+		return
+	}
+
+	server.mutex.Lock()
+	if server.closed {
+		server.mutex.Unlock()
+		conn.Close()
+		return
+	}
+	server.clients[c.id] = c
+	server.mutex.Unlock()
+
+	if server.config.OnConnect != nil {
+		server.config.OnConnect(c.id)
+	}
+	go server.write(c)
+	go server.read(c)
+}
+
+// Queue the valid inputs of the client until it disconnects
+// Disconnects the client advertising a world format version it can't read
+func (server *Server) read(c *client) {
+	for {
+		data, err := c.conn.Receive()
+		if err != nil {
+			server.disconnect(c)
+			return
+		}
+		if version, err := decodeHello(data); err == nil {
+			if err := engine.CheckReaderVersion(version); err != nil {
+				server.report(c, err)
+				server.disconnect(c)
+				return
+			}
+			continue
+		}
+		input, err := decodeInput(data)
+		if err != nil {
+			continue // Garbage or a handshake datagram
+		}
+		if server.config.Authorize == nil || !server.config.Authorize(c.id, input) {
+			continue
+		}
+		server.mutex.Lock()
+		if c.pending < maxPendingInputs {
+			c.pending++
+			server.pending = append(server.pending, clientInput{client: c, input: input})
+		}
+		server.mutex.Unlock()
+	}
+}
+
+// Send the queued messages to the client until it disconnects
+func (server *Server) write(c *client) {
+	for {
+		select {
+		case message := <-c.outbox:
+			err := c.conn.Send(message)
+			if errors.Is(err, ErrMessageTooLarge) {
+				server.report(c, err)
+				continue
+			}
+			if err != nil {
+				server.disconnect(c)
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// Close the client connection and forget the client
+func (server *Server) disconnect(c *client) {
+	c.once.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+		server.mutex.Lock()
+		delete(server.clients, c.id)
+		server.mutex.Unlock()
+		if server.config.OnDisconnect != nil {
+			server.config.OnDisconnect(c.id)
+		}
+	})
+}
+
+// Report the message dropped for the client
+func (server *Server) report(c *client, err error) {
+	if server.config.OnError != nil {
+		server.config.OnError(c.id, err)
+	}
+}
+
+// Get the tick rate or the default one
+func (server *Server) tickRate() float64 {
+	if server.config.TickRate <= 0 {
+		return defaultTickRate
+	}
+	return server.config.TickRate
+}
+
+// Queue the message, replacing the oldest one when the client doesn't keep up
+func (c *client) send(message []byte) {
+	select {
+	case c.outbox <- message:
+		return
+	default:
+	}
+	select {
+	case <-c.outbox:
+	default:
+	}
+	select {
+	case c.outbox <- message:
+	default:
+	}
+}
@@ -0,0 +1,418 @@
+package server_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plugfox/slash-engine-go/engine"
+	"github.com/plugfox/slash-engine-go/server"
+)
+
+// newServerEngine creates an engine with two creatures standing on the floor.
+func newServerEngine() *engine.Engine {
+	eng := &engine.Engine{}
+	eng.CreateWorld(0, engine.Vector{X: 1000, Y: 1000})
+	eng.UpsertObjects([]*engine.Object{
+		{ID: 1, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 100, Y: 20}},
+		{ID: 2, Type: engine.Creature, Size: engine.Vector{X: 20, Y: 40}, Position: engine.Vector{X: 500, Y: 20}},
+	})
+	return eng
+}
+
+// ownObject lets every client control the object with its id.
+func ownObject(clientID uint32, input engine.Input) bool {
+	return input.ObjectID == int(clientID)
+}
+
+// loopback is a transport listening on the loopback interface and a function connecting to it.
+type loopback struct {
+	name   string
+	listen func(t *testing.T) (server.Transport, func() (server.Conn, error))
+}
+
+var loopbacks = []loopback{
+	{"memory", func(t *testing.T) (server.Transport, func() (server.Conn, error)) {
+		transport := server.NewMemoryTransport()
+		return transport, transport.Dial
+	}},
+	{"udp", func(t *testing.T) (server.Transport, func() (server.Conn, error)) {
+		transport, err := server.ListenUDP("127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return transport, func() (server.Conn, error) { return server.DialUDP(transport.Addr()) }
+	}},
+	{"websocket", func(t *testing.T) (server.Transport, func() (server.Conn, error)) {
+		transport, err := server.ListenWebSocket("127.0.0.1:0", "/ws")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return transport, func() (server.Conn, error) { return server.DialWebSocket("ws://" + transport.Addr() + "/ws") }
+	}},
+}
+
+// connectClient dials the server and waits for the greeting.
+func connectClient(t *testing.T, dial func() (server.Conn, error)) *server.Client {
+	t.Helper()
+	conn, err := dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := server.NewClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// waitForSnapshot receives snapshots until the condition is met or the timeout expires.
+func waitForSnapshot(t *testing.T, client *server.Client, condition func(server.Snapshot) bool) server.Snapshot {
+	t.Helper()
+	result := make(chan server.Snapshot, 1)
+	failure := make(chan error, 1)
+	go func() {
+		for {
+			snapshot, err := client.Receive()
+			if err != nil {
+				failure <- err
+				return
+			}
+			if condition(snapshot) {
+				result <- snapshot
+				return
+			}
+		}
+	}()
+	select {
+	case snapshot := <-result:
+		return snapshot
+	case err := <-failure:
+		t.Fatalf("Receive failed: %v", err)
+	case <-time.After(2 * time.Second):
+		client.Close()
+		t.Fatal("Timed out waiting for the snapshot")
+	}
+	return server.Snapshot{}
+}
+
+func TestServerTwoClients(t *testing.T) {
+	for _, lb := range loopbacks {
+		t.Run(lb.name, func(t *testing.T) {
+			transport, dial := lb.listen(t)
+			srv := server.New(newServerEngine(), server.Config{TickRate: 100, Authorize: ownObject})
+			t.Cleanup(func() { srv.Close() })
+			go srv.Serve(transport)
+			srv.Start()
+
+			alice := connectClient(t, dial)
+			bob := connectClient(t, dial)
+			if alice.ID() == bob.ID() {
+				t.Fatalf("Expected distinct client ids, got %d and %d", alice.ID(), bob.ID())
+			}
+
+			if err := alice.SendInput(engine.Input{Sequence: 1, ObjectID: 1, Kind: engine.InputVelocity, Vector: engine.Vector{X: 100}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := bob.SendInput(engine.Input{Sequence: 1, ObjectID: 2, Kind: engine.InputVelocity, Vector: engine.Vector{X: -100}}); err != nil {
+				t.Fatal(err)
+			}
+
+			acked := waitForSnapshot(t, alice, func(s server.Snapshot) bool { return s.LastAck == 1 })
+			if v := acked.World.Objects[1].Velocity.X; v != 100 {
+				t.Errorf("Expected the acknowledged input to be applied, got velocity %f", v)
+			}
+
+			// Each client sees the input of the other one
+			for _, c := range []*server.Client{alice, bob} {
+				snapshot := waitForSnapshot(t, c, func(s server.Snapshot) bool {
+					return s.World.Objects[1].Velocity.X == 100 && s.World.Objects[2].Velocity.X == -100
+				})
+				if snapshot.World.Objects[1].Position.X <= 100 || snapshot.World.Objects[2].Position.X >= 500 {
+					t.Errorf("Expected both creatures to move, got %v and %v",
+						snapshot.World.Objects[1].Position, snapshot.World.Objects[2].Position)
+				}
+			}
+		})
+	}
+}
+
+func TestServerRejectsUnauthorizedAndStaleInputs(t *testing.T) {
+	transport := server.NewMemoryTransport()
+	srv := server.New(newServerEngine(), server.Config{Authorize: ownObject})
+	t.Cleanup(func() { srv.Close() })
+	go srv.Serve(transport)
+
+	client := connectClient(t, transport.Dial)
+	if client.ID() != 1 {
+		t.Fatalf("Expected the first client to get id 1, got %d", client.ID())
+	}
+	inputs := []engine.Input{
+		{Sequence: 1, ObjectID: 2, Kind: engine.InputVelocity, Vector: engine.Vector{X: -100}}, // Not owned
+		{Sequence: 3, ObjectID: 1, Kind: engine.InputVelocity, Vector: engine.Vector{X: 50}},
+		{Sequence: 2, ObjectID: 1, Kind: engine.InputVelocity, Vector: engine.Vector{X: 999}}, // Stale
+	}
+	for _, input := range inputs {
+		if err := client.SendInput(input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Step manually until the inputs arrive
+	received := make(chan server.Snapshot)
+	go func() {
+		for {
+			snapshot, err := client.Receive()
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- snapshot
+		}
+	}()
+	var snapshot server.Snapshot
+	for deadline := time.Now().Add(2 * time.Second); snapshot.LastAck != 3; {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the acknowledgement")
+		}
+		tick := srv.Step()
+		snapshot = <-received
+		if snapshot.Tick != tick {
+			t.Fatalf("Expected snapshot of tick %d, got %d", tick, snapshot.Tick)
+		}
+	}
+	srv.Step()
+	snapshot = <-received
+
+	if v := snapshot.World.Objects[1].Velocity.X; v != 50 {
+		t.Errorf("Expected the stale input to be ignored, got velocity %f", v)
+	}
+	if v := snapshot.World.Objects[2].Velocity.X; v != 0 {
+		t.Errorf("Expected the unauthorized input to be rejected, got velocity %f", v)
+	}
+}
+
+func TestServerRejectsInputsWithoutAuthorize(t *testing.T) {
+	transport := server.NewMemoryTransport()
+	srv := server.New(newServerEngine(), server.Config{})
+	t.Cleanup(func() { srv.Close() })
+	go srv.Serve(transport)
+
+	client := connectClient(t, transport.Dial)
+	if err := client.SendInput(engine.Input{Sequence: 1, ObjectID: 1, Kind: engine.InputVelocity, Vector: engine.Vector{X: 100}}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond) // Let the server read the input
+	srv.Step()
+	snapshot := waitForSnapshot(t, client, func(server.Snapshot) bool { return true })
+	if snapshot.LastAck != 0 || snapshot.World.Objects[1].Velocity.X != 0 {
+		t.Errorf("Expected the input to be rejected without Authorize, got ack %d and velocity %f",
+			snapshot.LastAck, snapshot.World.Objects[1].Velocity.X)
+	}
+}
+
+func TestServerBoundsPendingInputs(t *testing.T) {
+	const sent = 100
+	authorized := make(chan struct{}, sent)
+	transport := server.NewMemoryTransport()
+	srv := server.New(newServerEngine(), server.Config{
+		Authorize: func(clientID uint32, input engine.Input) bool {
+			authorized <- struct{}{}
+			return true
+		},
+	})
+	t.Cleanup(func() { srv.Close() })
+	go srv.Serve(transport)
+
+	client := connectClient(t, transport.Dial)
+	for sequence := uint32(1); sequence <= sent; sequence++ {
+		input := engine.Input{Sequence: sequence, ObjectID: 1, Kind: engine.InputVelocity, Vector: engine.Vector{X: float64(sequence)}}
+		if err := client.SendInput(input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < sent; i++ {
+		select {
+		case <-authorized:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for the inputs")
+		}
+	}
+
+	// Only the inputs queued before the limit are applied in the tick
+	srv.Step()
+	snapshot := waitForSnapshot(t, client, func(server.Snapshot) bool { return true })
+	if snapshot.LastAck != 64 || snapshot.World.Objects[1].Velocity.X != 64 {
+		t.Errorf("Expected 64 inputs to be applied, got ack %d and velocity %f", snapshot.LastAck, snapshot.World.Objects[1].Velocity.X)
+	}
+}
+
+// limitedTransport wraps the connections so they can't send messages longer than the limit.
+type limitedTransport struct {
+	server.Transport
+	limit int
+}
+
+type limitedConn struct {
+	server.Conn
+	limit int
+}
+
+func (transport limitedTransport) Accept() (server.Conn, error) {
+	conn, err := transport.Transport.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return limitedConn{Conn: conn, limit: transport.limit}, nil
+}
+
+func (conn limitedConn) Send(data []byte) error {
+	if len(data) > conn.limit {
+		return server.ErrMessageTooLarge
+	}
+	return conn.Conn.Send(data)
+}
+
+func TestServerReportsDroppedSnapshots(t *testing.T) {
+	type report struct {
+		clientID uint32
+		err      error
+	}
+	reports := make(chan report, 1)
+	disconnected := make(chan uint32, 1)
+	transport := server.NewMemoryTransport()
+	srv := server.New(newServerEngine(), server.Config{
+		OnError: func(clientID uint32, err error) {
+			select {
+			case reports <- report{clientID, err}:
+			default:
+			}
+		},
+		OnDisconnect: func(clientID uint32) { disconnected <- clientID },
+	})
+	t.Cleanup(func() { srv.Close() })
+	go srv.Serve(limitedTransport{Transport: transport, limit: 64})
+
+	client := connectClient(t, transport.Dial)
+	srv.Step()
+	select {
+	case got := <-reports:
+		if got.clientID != client.ID() || !errors.Is(got.err, server.ErrMessageTooLarge) {
+			t.Errorf("Expected ErrMessageTooLarge for client %d, got %v for client %d", client.ID(), got.err, got.clientID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the dropped snapshot report")
+	}
+	select {
+	case id := <-disconnected:
+		t.Errorf("Expected the client to stay connected, client %d disconnected", id)
+	default:
+	}
+}
+
+func TestServerLargeWorld(t *testing.T) {
+	for _, lb := range loopbacks {
+		t.Run(lb.name, func(t *testing.T) {
+			eng := newServerEngine()
+			objects := make([]*engine.Object, 0, 2000)
+			for id := 10; len(objects) < cap(objects); id++ {
+				objects = append(objects, &engine.Object{ID: id, Type: engine.Item, Size: engine.Vector{X: 1, Y: 1}, Position: engine.Vector{X: float64(id % 1000), Y: 0.5}})
+			}
+			eng.UpsertObjects(objects)
+			if size := len(eng.GetWorldBytes()); size <= 65507 {
+				t.Fatalf("Expected the world to exceed a UDP datagram, got %d bytes", size)
+			}
+
+			transport, dial := lb.listen(t)
+			srv := server.New(eng, server.Config{TickRate: 100})
+			t.Cleanup(func() { srv.Close() })
+			go srv.Serve(transport)
+			srv.Start()
+
+			client := connectClient(t, dial)
+			received := make(chan server.Snapshot, 1)
+			go func() {
+				if snapshot, err := client.Receive(); err == nil {
+					received <- snapshot
+				}
+			}()
+			select {
+			case snapshot := <-received:
+				if len(snapshot.World.Objects) != 2002 {
+					t.Errorf("Expected 2002 objects, got %d", len(snapshot.World.Objects))
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timed out waiting for the large snapshot")
+			}
+		})
+	}
+}
+
+func TestServerNegotiatesFormatVersion(t *testing.T) {
+	reports := make(chan error, 1)
+	disconnected := make(chan uint32, 1)
+	transport := server.NewMemoryTransport()
+	srv := server.New(newServerEngine(), server.Config{
+		OnError:      func(clientID uint32, err error) { reports <- err },
+		OnDisconnect: func(clientID uint32) { disconnected <- clientID },
+	})
+	t.Cleanup(func() { srv.Close() })
+	go srv.Serve(transport)
+
+	// A current client reads the snapshots
+	connectClient(t, transport.Dial)
+	select {
+	case err := <-reports:
+		t.Fatalf("Expected the current client to be accepted, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A client advertising the format 0 can't read them
+	conn, err := transport.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if _, err := conn.Receive(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Send([]byte{4, 0, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reports:
+		if !errors.Is(err, engine.ErrIncompatibleFormat) {
+			t.Errorf("Expected ErrIncompatibleFormat, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the incompatible client report")
+	}
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the incompatible client to be disconnected")
+	}
+}
+
+func TestNewClientRejectsNewerServer(t *testing.T) {
+	transport := server.NewMemoryTransport()
+	t.Cleanup(func() { transport.Close() })
+	go func() {
+		conn, err := transport.Accept()
+		if err != nil {
+			return
+		}
+		// Welcome of the client 1 by a server writing snapshots only newer engines read
+		newer := engine.FormatVersion + 1
+		conn.Send([]byte{1, 1, 0, 0, 0, byte(newer), 0, 0, 0, byte(newer), 0, 0, 0})
+	}()
+
+	conn, err := transport.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.NewClient(conn); !errors.Is(err, engine.ErrIncompatibleFormat) {
+		t.Errorf("Expected ErrIncompatibleFormat, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import "errors"
+
+// ErrClosed is returned by the transports and connections after they are closed
+var ErrClosed = errors.New("server: connection closed")
+
+// ErrHandshakeTimeout is returned by DialUDP when the server doesn't accept the client in time
+var ErrHandshakeTimeout = errors.New("server: handshake timed out")
+
+// ErrMessageTooLarge is returned when the message doesn't fit into the transport frame
+var ErrMessageTooLarge = errors.New("server: message too large")
+
+// Transport accepts client connections, e.g. over UDP, WebSocket or in memory
+type Transport interface {
+	// Wait for the next client connection
+	// Returns ErrClosed after the transport is closed
+	Accept() (Conn, error)
+
+	// Stop accepting connections and release the resources
+	Close() error
+
+	// Address the transport listens on
+	Addr() string
+}
+
+// Conn is a message-oriented connection between the server and a client
+// Send and Receive are safe to call from different goroutines
+type Conn interface {
+	// Send a whole message to the other side
+	Send(data []byte) error
+
+	// Wait for the next whole message from the other side
+	// Returns ErrClosed (or the transport error) after the connection is closed
+	Receive() ([]byte, error)
+
+	// Close the connection
+	Close() error
+}
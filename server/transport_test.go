@@ -0,0 +1,373 @@
+package server_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/plugfox/slash-engine-go/server"
+)
+
+// acceptAll accepts the connections of the transport in the background until it's closed.
+func acceptAll(transport server.Transport) <-chan server.Conn {
+	accepted := make(chan server.Conn, 16)
+	go func() {
+		for {
+			conn, err := transport.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	return accepted
+}
+
+// acceptWithin waits for the next accepted connection, returns nil after the timeout.
+func acceptWithin(accepted <-chan server.Conn, timeout time.Duration) server.Conn {
+	select {
+	case conn := <-accepted:
+		return conn
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+// receiveWithin waits for the next message of the connection or its error.
+func receiveWithin(t *testing.T, conn server.Conn, timeout time.Duration) ([]byte, error) {
+	t.Helper()
+	type result struct {
+		data []byte
+		err  error
+	}
+	received := make(chan result, 1)
+	go func() {
+		data, err := conn.Receive()
+		received <- result{data, err}
+	}()
+	select {
+	case got := <-received:
+		return got.data, got.err
+	case <-time.After(timeout):
+		t.Fatal("Timed out waiting for the message")
+		return nil, nil
+	}
+}
+
+func TestUDPFragmentsLargeMessages(t *testing.T) {
+	transport, err := server.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	accepted := acceptAll(transport)
+	client, err := server.DialUDP(transport.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	peer := acceptWithin(accepted, 2*time.Second)
+	if peer == nil {
+		t.Fatal("Timed out waiting for the client")
+	}
+
+	message := make([]byte, 150_000)
+	for i := range message {
+		message[i] = byte(i * 7)
+	}
+	for _, direction := range []struct {
+		name     string
+		from, to server.Conn
+	}{{"to client", peer, client}, {"to server", client, peer}} {
+		if err := direction.from.Send(message); err != nil {
+			t.Fatal(err)
+		}
+		got, err := receiveWithin(t, direction.to, 2*time.Second)
+		if err != nil || !bytes.Equal(got, message) {
+			t.Errorf("Expected the message %s to be reassembled, got %d bytes and %v", direction.name, len(got), err)
+		}
+	}
+
+	if err := peer.Send(make([]byte, 16<<20+1)); !errors.Is(err, server.ErrMessageTooLarge) {
+		t.Errorf("Expected ErrMessageTooLarge, got %v", err)
+	}
+}
+
+// readWithin reads the next datagram of the raw socket, nil after the timeout.
+func readWithin(conn *net.UDPConn, timeout time.Duration) []byte {
+	buffer := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+	n, err := conn.Read(buffer)
+	if err != nil {
+		return nil
+	}
+	return buffer[:n]
+}
+
+// udpHandshake gets the cookie for the raw socket and echoes it back.
+func udpHandshake(t *testing.T, conn *net.UDPConn) {
+	t.Helper()
+	conn.Write(append([]byte{2}, make([]byte, 16)...))
+	challenge := readWithin(conn, 2*time.Second)
+	if len(challenge) != 17 || challenge[0] != 3 {
+		t.Fatalf("Expected the challenge, got %v", challenge)
+	}
+	challenge[0] = 4
+	conn.Write(challenge)
+}
+
+func TestUDPRequiresHandshake(t *testing.T) {
+	transport, err := server.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	accepted := acceptAll(transport)
+	addr, err := net.ResolveUDPAddr("udp", transport.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	// Datagrams without the handshake, a short hello and a wrong cookie get neither a client nor an answer
+	for _, datagram := range [][]byte{nil, {0, 42}, {2}, append([]byte{4}, make([]byte, 16)...)} {
+		conn.Write(datagram)
+		if reply := readWithin(conn, 100*time.Millisecond); reply != nil {
+			t.Errorf("Expected no answer to %v, got %v", datagram, reply)
+		}
+	}
+	if conn := acceptWithin(accepted, 50*time.Millisecond); conn != nil {
+		t.Fatal("Expected no client before the handshake")
+	}
+
+	// The echoed cookie is accepted and welcomed
+	udpHandshake(t, conn)
+	if welcome := readWithin(conn, 2*time.Second); len(welcome) != 1 || welcome[0] != 5 {
+		t.Errorf("Expected the welcome, got %v", welcome)
+	}
+	if conn := acceptWithin(accepted, 2*time.Second); conn == nil {
+		t.Fatal("Expected the client to be accepted after the handshake")
+	}
+}
+
+func TestUDPLimitsAndExpiresPeers(t *testing.T) {
+	transport, err := server.ListenUDPWithConfig("127.0.0.1:0", server.UDPConfig{MaxPeers: 1, IdleTimeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	accepted := acceptAll(transport)
+	addr, err := net.ResolveUDPAddr("udp", transport.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Raw sockets don't send keepalives
+	dial := func() *net.UDPConn {
+		conn, err := net.DialUDP("udp", nil, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	first := dial()
+	udpHandshake(t, first)
+	firstPeer := acceptWithin(accepted, 2*time.Second)
+	if firstPeer == nil {
+		t.Fatal("Timed out waiting for the first client")
+	}
+
+	// The second client is over the limit, the datagram of the first one is read after its handshake
+	second := dial()
+	udpHandshake(t, second)
+	first.Write([]byte{0, 42})
+	if data, err := receiveWithin(t, firstPeer, 2*time.Second); err != nil || !bytes.Equal(data, []byte{42}) {
+		t.Fatalf("Expected the message of the first client, got %v and %v", data, err)
+	}
+	if conn := acceptWithin(accepted, 50*time.Millisecond); conn != nil {
+		t.Fatal("Expected the client over the limit to be dropped")
+	}
+
+	// The silent first client expires and frees the place
+	if _, err := receiveWithin(t, firstPeer, 2*time.Second); !errors.Is(err, server.ErrClosed) {
+		t.Fatalf("Expected the idle client to be closed, got %v", err)
+	}
+	udpHandshake(t, second)
+	if conn := acceptWithin(accepted, 2*time.Second); conn == nil {
+		t.Fatal("Expected the second client to be accepted after the first one expired")
+	}
+}
+
+// upgradeStatus sends a WebSocket upgrade request with the origin and returns the response status.
+func upgradeStatus(t *testing.T, address, origin string) int {
+	t.Helper()
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	request, err := http.NewRequest(http.MethodGet, "http://"+address+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	if origin != "" {
+		request.Header.Set("Origin", origin)
+	}
+	if err := request.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.ReadResponse(bufio.NewReader(conn), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	return response.StatusCode
+}
+
+func TestWebSocketChecksOrigin(t *testing.T) {
+	transport, err := server.ListenWebSocket("127.0.0.1:0", "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	go func() {
+		for {
+			conn, err := transport.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	for _, tc := range []struct {
+		origin string
+		status int
+	}{
+		{"", http.StatusSwitchingProtocols},
+		{"http://" + transport.Addr(), http.StatusSwitchingProtocols},
+		{"http://evil.example", http.StatusForbidden},
+	} {
+		if status := upgradeStatus(t, transport.Addr(), tc.origin); status != tc.status {
+			t.Errorf("Expected status %d for origin %q, got %d", tc.status, tc.origin, status)
+		}
+	}
+
+	custom, err := server.ListenWebSocketWithConfig("127.0.0.1:0", "/ws", server.WebSocketConfig{
+		CheckOrigin: func(r *http.Request) bool { return r.Header.Get("Origin") == "http://game.example" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { custom.Close() })
+	go custom.Accept()
+	if status := upgradeStatus(t, custom.Addr(), "http://game.example"); status != http.StatusSwitchingProtocols {
+		t.Errorf("Expected the allowed origin to be accepted, got %d", status)
+	}
+}
+
+func TestWebSocketKeepAlive(t *testing.T) {
+	transport, err := server.ListenWebSocketWithConfig("127.0.0.1:0", "/ws", server.WebSocketConfig{
+		PingInterval: 20 * time.Millisecond,
+		ReadTimeout:  200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	accepted := acceptAll(transport)
+	dial := func() (server.Conn, server.Conn) {
+		client, err := server.DialWebSocket("ws://" + transport.Addr() + "/ws")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { client.Close() })
+		peer := acceptWithin(accepted, 2*time.Second)
+		if peer == nil {
+			t.Fatal("Timed out waiting for the client")
+		}
+		return client, peer
+	}
+
+	// The client answers the pings while it receives
+	answering, answeringPeer := dial()
+	messages := make(chan []byte, 1)
+	go func() {
+		for {
+			data, err := answering.Receive()
+			if err != nil {
+				return
+			}
+			messages <- data
+		}
+	}()
+	answeringFailed := make(chan error, 1)
+	go func() {
+		_, err := answeringPeer.Receive()
+		answeringFailed <- err
+	}()
+
+	// The silent client doesn't answer, so the server gives up on it
+	_, silentPeer := dial()
+	if _, err := receiveWithin(t, silentPeer, 2*time.Second); err == nil {
+		t.Fatal("Expected the silent client to time out")
+	}
+
+	select {
+	case err := <-answeringFailed:
+		t.Fatalf("Expected the answering client to stay connected, got %v", err)
+	default:
+	}
+	if err := answeringPeer.Send([]byte{42}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case data := <-messages:
+		if !bytes.Equal(data, []byte{42}) {
+			t.Errorf("Expected message [42], got %v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the message")
+	}
+}
+
+func TestWebSocketWriteTimeout(t *testing.T) {
+	transport, err := server.ListenWebSocketWithConfig("127.0.0.1:0", "/ws", server.WebSocketConfig{WriteTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	accepted := acceptAll(transport)
+	client, err := server.DialWebSocket("ws://" + transport.Addr() + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	peer := acceptWithin(accepted, 2*time.Second)
+	if peer == nil {
+		t.Fatal("Timed out waiting for the client")
+	}
+
+	// The client doesn't read, so the socket buffers fill up and the send times out
+	message := make([]byte, 1<<20)
+	for i := 0; i < 256; i++ {
+		if err := peer.Send(message); err != nil {
+			return
+		}
+	}
+	t.Fatal("Expected the send to a stuck client to time out")
+}
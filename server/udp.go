@@ -0,0 +1,558 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Maximum payload of a UDP datagram over IPv4
+const maxDatagramSize = 65507
+
+// Maximum size of a message split into several datagrams, larger ones return ErrMessageTooLarge
+const maxUDPMessageSize = 16 << 20
+
+// Number of received messages buffered per client, newer ones are dropped when it's full
+const udpQueueSize = 256
+
+// Number of new clients waiting for Accept, newer ones are dropped until they send again
+const udpAcceptQueueSize = 64
+
+// Number of messages reassembled at once per client, the oldest one is dropped when a new one starts
+const udpPartialMessages = 4
+
+// Interval of the empty datagrams the client sends to keep the server from expiring it
+const udpKeepAlive = 5 * time.Second
+
+// Interval of the handshake retries of DialUDP and the time it waits for the server
+const (
+	udpHandshakeRetry   = 250 * time.Millisecond
+	udpHandshakeTimeout = 5 * time.Second
+)
+
+// Lifetime of a handshake cookie, a cookie stays valid for one to two lifetimes
+const udpCookieLifetime = 10 * time.Second
+
+// Size of the handshake cookie
+const udpCookieSize = 16
+
+// Defaults of UDPConfig
+const (
+	defaultUDPMaxPeers    = 1024
+	defaultUDPIdleTimeout = 30 * time.Second
+)
+
+// Datagram kinds, the first byte of a non-empty datagram
+const (
+	udpWhole     byte = 0 // The rest is the whole message
+	udpFragment  byte = 1 // Message id, fragment index and count, then the fragment
+	udpHello     byte = 2 // Client asks for a cookie, zero padding up to the size of the challenge
+	udpChallenge byte = 3 // Server sends the cookie for the client address
+	udpConnect   byte = 4 // Client echoes the cookie to be accepted
+	udpWelcome   byte = 5 // Server accepted the client
+)
+
+// Size of the handshake datagrams with a cookie: kind and cookie
+// The hello is padded to the same size, so the server never answers with more bytes than it gets
+const udpHandshakeSize = 1 + udpCookieSize
+
+// Size of the fragment header: kind, message id, index and count
+const udpFragmentHeaderSize = 1 + 4 + 2 + 2
+
+// Size of the message part carried by a fragment
+const udpFragmentSize = maxDatagramSize - udpFragmentHeaderSize
+
+// UDPConfig tunes the UDP transport, zero values select the defaults
+type UDPConfig struct {
+	// Maximum number of clients, handshakes of new addresses are dropped while it's reached, 1024 by default
+	MaxPeers int
+
+	// Clients sending nothing for this long are disconnected, 30 seconds by default
+	// The clients made by DialUDP send an empty datagram every 5 seconds, so keep it a few times longer
+	IdleTimeout time.Duration
+}
+
+// UDPTransport accepts clients over UDP
+// A client is identified by its address and accepted after a cookie handshake:
+// the client sends a hello, the server answers with a cookie derived from the client address and a secret,
+// and the client echoes it back, proving it receives the datagrams sent to its address
+// The server keeps nothing and sends nothing but the cookie to the addresses that didn't finish the handshake,
+// so spoofed source addresses can't get the snapshots reflected to them or take the places of the clients
+// Messages larger than a datagram are split into fragments and reassembled on the other side,
+// delivery and order are not guaranteed, a message with a lost fragment is lost as a whole
+type UDPTransport struct {
+	conn   *net.UDPConn
+	config UDPConfig
+	mutex  sync.Mutex
+	secret []byte // Key of the handshake cookies
+	peers  map[string]*udpConn
+	accept chan Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// Server side of a UDP client
+type udpConn struct {
+	transport *UDPTransport
+	addr      *net.UDPAddr
+	in        chan []byte
+	seen      atomic.Int64 // Unix time in nanoseconds of the last datagram
+	assembler udpAssembler // Used only by the read goroutine of the transport
+	lastID    atomic.Uint32
+	closed    chan struct{}
+	once      sync.Once
+}
+
+// Client side of a UDP connection
+type udpClientConn struct {
+	conn      *net.UDPConn
+	assembler udpAssembler // Used only by Receive
+	lastID    atomic.Uint32
+	closed    chan struct{}
+	once      sync.Once
+}
+
+// Reassembles the fragmented messages of a single sender
+type udpAssembler struct {
+	partials []*udpPartial
+}
+
+// Message being reassembled
+type udpPartial struct {
+	id        uint32
+	fragments [][]byte
+	received  int
+}
+
+// Listen for UDP clients on the address, e.g. "127.0.0.1:9000"
+func ListenUDP(address string) (*UDPTransport, error) {
+	return ListenUDPWithConfig(address, UDPConfig{})
+}
+
+// Listen for UDP clients on the address with the config
+func ListenUDPWithConfig(address string, config UDPConfig) (*UDPTransport, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxPeers <= 0 {
+		config.MaxPeers = defaultUDPMaxPeers
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaultUDPIdleTimeout
+	}
+	secret := make([]byte, sha256.Size)
+	if _, err := rand.Read(secret); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	transport := &UDPTransport{
+		conn:   conn,
+		config: config,
+		secret: secret,
+		peers:  make(map[string]*udpConn),
+		accept: make(chan Conn, udpAcceptQueueSize),
+		closed: make(chan struct{}),
+	}
+	go transport.read()
+	go transport.expire()
+	return transport, nil
+}
+
+// Connect to a UDP server on the address
+// Waits until the server accepts the client after the cookie handshake (see UDPTransport),
+// then sends an empty datagram every few seconds until closed, so the server doesn't expire an idle client
+func DialUDP(address string) (Conn, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := _udpHandshake(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	client := &udpClientConn{conn: conn, closed: make(chan struct{})}
+	go client.keepAlive()
+	return client, nil
+}
+
+// Send the hello and the cookie of the server until it welcomes the client
+// Lost datagrams are sent again, returns an error if the server doesn't answer in time
+func _udpHandshake(conn *net.UDPConn) error {
+	defer conn.SetReadDeadline(time.Time{})
+	request := make([]byte, udpHandshakeSize)
+	request[0] = udpHello
+	buffer := make([]byte, maxDatagramSize+1)
+	deadline := time.Now().Add(udpHandshakeTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := conn.Write(request); err != nil {
+			return err
+		}
+		conn.SetReadDeadline(time.Now().Add(udpHandshakeRetry))
+	read:
+		for {
+			n, err := conn.Read(buffer)
+			var timeout net.Error
+			switch {
+			case errors.As(err, &timeout) && timeout.Timeout():
+				break read // Send the request again
+			case err != nil:
+				continue // E.g. ICMP port unreachable before the server starts
+			case n == 1 && buffer[0] == udpWelcome && request[0] == udpConnect:
+				return nil
+			case n == udpHandshakeSize && buffer[0] == udpChallenge:
+				request = append(request[:0], buffer[:n]...)
+				request[0] = udpConnect
+				break read // Echo the cookie right away
+			}
+			// Stale datagram of an earlier step
+		}
+	}
+	return ErrHandshakeTimeout
+}
+
+func (transport *UDPTransport) Accept() (Conn, error) {
+	select {
+	case conn := <-transport.accept:
+		return conn, nil
+	case <-transport.closed:
+		return nil, ErrClosed
+	}
+}
+
+func (transport *UDPTransport) Close() error {
+	var err error
+	transport.once.Do(func() {
+		close(transport.closed)
+		err = transport.conn.Close()
+	})
+	return err
+}
+
+func (transport *UDPTransport) Addr() string {
+	return transport.conn.LocalAddr().String()
+}
+
+// Read the datagrams and route them to the clients until the transport is closed
+func (transport *UDPTransport) read() {
+	buffer := make([]byte, maxDatagramSize+1)
+	for {
+		n, addr, err := transport.conn.ReadFromUDP(buffer)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue // E.g. ICMP port unreachable from a gone client
+		}
+		if n > maxDatagramSize {
+			continue
+		}
+		if n > 0 && (buffer[0] == udpHello || buffer[0] == udpConnect) {
+			transport.handshake(buffer[:n], addr)
+			continue
+		}
+
+		peer := transport.knownPeer(addr)
+		if peer == nil {
+			continue // No handshake, the address may be spoofed
+		}
+		peer.seen.Store(time.Now().UnixNano())
+		if n == 0 {
+			continue // Keepalive datagram
+		}
+		message, ok := peer.assembler.add(buffer[:n])
+		if !ok {
+			continue
+		}
+		select {
+		case peer.in <- message:
+		default: // The client doesn't keep up, drop like the network would
+		}
+	}
+}
+
+// Answer the hello with the cookie, accept the client echoing a valid cookie and welcome it
+// A hello shorter than the challenge is dropped, so the answer never amplifies a spoofed datagram
+func (transport *UDPTransport) handshake(datagram []byte, addr *net.UDPAddr) {
+	if len(datagram) != udpHandshakeSize {
+		return
+	}
+	now := time.Now()
+	if datagram[0] == udpHello {
+		challenge := append([]byte{udpChallenge}, transport.cookie(addr, now, 0)...)
+		transport.conn.WriteToUDP(challenge, addr)
+		return
+	}
+	cookie := datagram[1:]
+	if !hmac.Equal(cookie, transport.cookie(addr, now, 0)) && !hmac.Equal(cookie, transport.cookie(addr, now, 1)) {
+		return // Forged or expired cookie
+	}
+
+	peer, accepted := transport.peer(addr)
+	if peer == nil {
+		return // Too many clients
+	}
+	peer.seen.Store(now.UnixNano())
+	if accepted {
+		select {
+		case transport.accept <- peer:
+		default:
+			peer.Close() // Nobody accepts the clients, the client sends its cookie again
+			return
+		}
+	}
+	transport.conn.WriteToUDP([]byte{udpWelcome}, addr)
+}
+
+// Get the cookie of the address for the lifetime window of the time, age 1 is the previous window
+func (transport *UDPTransport) cookie(addr *net.UDPAddr, now time.Time, age int64) []byte {
+	mac := hmac.New(sha256.New, transport.secret)
+	var window [8]byte
+	binary.BigEndian.PutUint64(window[:], uint64(now.UnixNano()/int64(udpCookieLifetime)-age))
+	mac.Write(window[:])
+	mac.Write([]byte(addr.String()))
+	return mac.Sum(nil)[:udpCookieSize]
+}
+
+// Get the accepted client with the address, nil if there's none
+func (transport *UDPTransport) knownPeer(addr *net.UDPAddr) *udpConn {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	return transport.peers[addr.String()]
+}
+
+// Get the client with the address, creating a new one unless there are too many
+// Reports whether the client is new and must be accepted
+func (transport *UDPTransport) peer(addr *net.UDPAddr) (*udpConn, bool) {
+	key := addr.String()
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	if peer, ok := transport.peers[key]; ok {
+		return peer, false
+	}
+	if len(transport.peers) >= transport.config.MaxPeers {
+		return nil, false
+	}
+	peer := &udpConn{transport: transport, addr: addr, in: make(chan []byte, udpQueueSize), closed: make(chan struct{})}
+	transport.peers[key] = peer
+	return peer, true
+}
+
+// Close the clients sending nothing for the idle timeout until the transport is closed
+func (transport *UDPTransport) expire() {
+	timeout := transport.config.IdleTimeout
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			var idle []*udpConn
+			transport.mutex.Lock()
+			for _, peer := range transport.peers {
+				if now.Sub(time.Unix(0, peer.seen.Load())) > timeout {
+					idle = append(idle, peer)
+				}
+			}
+			transport.mutex.Unlock()
+			for _, peer := range idle {
+				peer.Close()
+			}
+		case <-transport.closed:
+			return
+		}
+	}
+}
+
+func (conn *udpConn) Send(data []byte) error {
+	select {
+	case <-conn.closed:
+		return ErrClosed
+	case <-conn.transport.closed:
+		return ErrClosed
+	default:
+	}
+	datagrams, err := _udpDatagrams(data, conn.lastID.Add(1))
+	if err != nil {
+		return err
+	}
+	for _, datagram := range datagrams {
+		if _, err := conn.transport.conn.WriteToUDP(datagram, conn.addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conn *udpConn) Receive() ([]byte, error) {
+	select {
+	case message := <-conn.in:
+		return message, nil
+	case <-conn.closed:
+		return nil, ErrClosed
+	case <-conn.transport.closed:
+		return nil, ErrClosed
+	}
+}
+
+func (conn *udpConn) Close() error {
+	conn.once.Do(func() {
+		close(conn.closed)
+		conn.transport.mutex.Lock()
+		if conn.transport.peers[conn.addr.String()] == conn {
+			delete(conn.transport.peers, conn.addr.String())
+		}
+		conn.transport.mutex.Unlock()
+	})
+	return nil
+}
+
+func (conn *udpClientConn) Send(data []byte) error {
+	datagrams, err := _udpDatagrams(data, conn.lastID.Add(1))
+	if err != nil {
+		return err
+	}
+	for _, datagram := range datagrams {
+		if _, err := conn.conn.Write(datagram); err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return ErrClosed
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (conn *udpClientConn) Receive() ([]byte, error) {
+	buffer := make([]byte, maxDatagramSize+1)
+	for {
+		n, err := conn.conn.Read(buffer)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil, ErrClosed
+			}
+			return nil, err
+		}
+		if n == 0 || n > maxDatagramSize {
+			continue
+		}
+		if message, ok := conn.assembler.add(buffer[:n]); ok {
+			return message, nil
+		}
+	}
+}
+
+func (conn *udpClientConn) Close() error {
+	var err error
+	conn.once.Do(func() {
+		close(conn.closed)
+		err = conn.conn.Close()
+	})
+	return err
+}
+
+// Send the empty datagrams to the server until the connection is closed
+func (conn *udpClientConn) keepAlive() {
+	ticker := time.NewTicker(udpKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			conn.conn.Write(nil)
+		case <-conn.closed:
+			return
+		}
+	}
+}
+
+// Add the datagram, returns the copy of the message when it's complete
+func (assembler *udpAssembler) add(datagram []byte) ([]byte, bool) {
+	switch datagram[0] {
+	case udpWhole:
+		return append([]byte{}, datagram[1:]...), true
+	case udpFragment:
+	default:
+		return nil, false
+	}
+	if len(datagram) <= udpFragmentHeaderSize {
+		return nil, false
+	}
+	id := binary.BigEndian.Uint32(datagram[1:])
+	index := int(binary.BigEndian.Uint16(datagram[5:]))
+	count := int(binary.BigEndian.Uint16(datagram[7:]))
+	fragment := datagram[udpFragmentHeaderSize:]
+	if count < 2 || index >= count || count > (maxUDPMessageSize+udpFragmentSize-1)/udpFragmentSize ||
+		(index < count-1 && len(fragment) != udpFragmentSize) {
+		return nil, false
+	}
+
+	var partial *udpPartial
+	for _, candidate := range assembler.partials {
+		if candidate.id == id {
+			partial = candidate
+			break
+		}
+	}
+	if partial == nil {
+		if len(assembler.partials) >= udpPartialMessages {
+			assembler.partials = assembler.partials[1:]
+		}
+		partial = &udpPartial{id: id, fragments: make([][]byte, count)}
+		assembler.partials = append(assembler.partials, partial)
+	}
+	if len(partial.fragments) != count || partial.fragments[index] != nil {
+		return nil, false // Duplicated or inconsistent fragment
+	}
+	partial.fragments[index] = append([]byte{}, fragment...)
+	partial.received++
+	if partial.received < count {
+		return nil, false
+	}
+
+	for i, candidate := range assembler.partials {
+		if candidate == partial {
+			assembler.partials = append(assembler.partials[:i], assembler.partials[i+1:]...)
+			break
+		}
+	}
+	message := make([]byte, 0, (count-1)*udpFragmentSize+len(partial.fragments[count-1]))
+	for _, fragment := range partial.fragments {
+		message = append(message, fragment...)
+	}
+	return message, true
+}
+
+// Split the message into the datagrams, a single one when it fits
+func _udpDatagrams(data []byte, id uint32) ([][]byte, error) {
+	if len(data) > maxUDPMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	if len(data) < maxDatagramSize {
+		return [][]byte{append([]byte{udpWhole}, data...)}, nil
+	}
+	count := (len(data) + udpFragmentSize - 1) / udpFragmentSize
+	datagrams := make([][]byte, 0, count)
+	for index := 0; index < count; index++ {
+		fragment := data[index*udpFragmentSize : min((index+1)*udpFragmentSize, len(data))]
+		datagram := make([]byte, udpFragmentHeaderSize, udpFragmentHeaderSize+len(fragment))
+		datagram[0] = udpFragment
+		binary.BigEndian.PutUint32(datagram[1:], id)
+		binary.BigEndian.PutUint16(datagram[5:], uint16(index))
+		binary.BigEndian.PutUint16(datagram[7:], uint16(count))
+		datagrams = append(datagrams, append(datagram, fragment...))
+	}
+	return datagrams, nil
+}
@@ -0,0 +1,542 @@
+package server
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Magic string of the handshake (RFC 6455, section 1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Maximum size of a received message, larger ones close the connection
+const maxWebSocketMessageSize = 16 << 20
+
+// Time to send the close frame before dropping the connection
+const closeTimeout = time.Second
+
+// Time to read the headers of the upgrade request served by ListenWebSocket
+const handshakeTimeout = 10 * time.Second
+
+// Defaults of WebSocketConfig
+const (
+	defaultPingInterval = 30 * time.Second
+	defaultReadTimeout  = 60 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+)
+
+// Frame opcodes (RFC 6455, section 5.2)
+const (
+	opContinuation byte = 0x0
+	opText         byte = 0x1
+	opBinary       byte = 0x2
+	opClose        byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xA
+)
+
+// Close status codes (RFC 6455, section 7.4.1)
+const (
+	closeNormal        = 1000
+	closeProtocolError = 1002
+	closeTooLarge      = 1009
+)
+
+var errProtocol = errors.New("server: websocket protocol error")
+
+// WebSocketConfig tunes the WebSocket transport, zero values select the defaults
+type WebSocketConfig struct {
+	// Check the Origin header of the upgrade request, e.g. against the pages allowed to connect
+	// Nil accepts the requests without the header (non-browser clients)
+	// and the ones from the pages on the same host the transport is served on
+	CheckOrigin func(r *http.Request) bool
+
+	// Interval of the pings keeping the connection alive, 30 seconds by default
+	PingInterval time.Duration
+
+	// Clients sending nothing, not even a pong, for this long are disconnected, 60 seconds by default
+	ReadTimeout time.Duration
+
+	// Clients not accepting a frame for this long are disconnected, 10 seconds by default
+	WriteTimeout time.Duration
+}
+
+// WebSocketTransport accepts clients over WebSocket, every message is a binary message
+// It's an http.Handler, so it can be mounted into an existing HTTP server
+// or served on its own with ListenWebSocket
+// The server pings the clients and disconnects the ones not answering, see WebSocketConfig
+type WebSocketTransport struct {
+	config   WebSocketConfig
+	listener net.Listener
+	http     *http.Server
+	accept   chan Conn
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// WebSocket connection, the client side masks the frames it sends
+type webSocketConn struct {
+	conn         net.Conn
+	reader       *bufio.Reader
+	client       bool
+	readTimeout  time.Duration // Zero disables the read deadline
+	writeTimeout time.Duration
+	sendMutex    sync.Mutex
+	closed       chan struct{}
+	once         sync.Once
+}
+
+// Create a WebSocket transport to mount into an HTTP server
+func NewWebSocketTransport() *WebSocketTransport {
+	return NewWebSocketTransportWithConfig(WebSocketConfig{})
+}
+
+// Create a WebSocket transport with the config to mount into an HTTP server
+func NewWebSocketTransportWithConfig(config WebSocketConfig) *WebSocketTransport {
+	if config.CheckOrigin == nil {
+		config.CheckOrigin = _sameOrigin
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = defaultPingInterval
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = defaultReadTimeout
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaultWriteTimeout
+	}
+	return &WebSocketTransport{
+		config: config,
+		accept: make(chan Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// Listen for WebSocket clients on the address, e.g. "127.0.0.1:9000", and the path, e.g. "/ws"
+func ListenWebSocket(address, path string) (*WebSocketTransport, error) {
+	return ListenWebSocketWithConfig(address, path, WebSocketConfig{})
+}
+
+// Listen for WebSocket clients on the address and the path with the config
+func ListenWebSocketWithConfig(address, path string, config WebSocketConfig) (*WebSocketTransport, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	transport := NewWebSocketTransportWithConfig(config)
+	mux := http.NewServeMux()
+	mux.Handle(path, transport)
+	transport.listener = listener
+	transport.http = &http.Server{Handler: mux, ReadHeaderTimeout: handshakeTimeout}
+	go transport.http.Serve(listener)
+	return transport, nil
+}
+
+// Connect to a WebSocket server, e.g. "ws://127.0.0.1:9000/ws"
+func DialWebSocket(rawURL string) (Conn, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != "ws" {
+		return nil, fmt.Errorf("server: unsupported websocket scheme %q", target.Scheme)
+	}
+	host := target.Host
+	if target.Port() == "" {
+		host = net.JoinHostPort(target.Hostname(), "80")
+	}
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	request := &http.Request{
+		Method:     http.MethodGet,
+		URL:        target,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Host:       target.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusSwitchingProtocols ||
+		response.Header.Get("Sec-WebSocket-Accept") != _acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("%w: handshake failed with status %s", errProtocol, response.Status)
+	}
+	return &webSocketConn{conn: conn, reader: reader, client: true, writeTimeout: defaultWriteTimeout, closed: make(chan struct{})}, nil
+}
+
+// Upgrade the HTTP request to a WebSocket connection and pass it to Accept
+func (transport *WebSocketTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet ||
+		!_headerContains(r.Header, "Connection", "upgrade") ||
+		!_headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return
+	}
+	if !transport.config.CheckOrigin(r) {
+		http.Error(w, "origin is not allowed", http.StatusForbidden)
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if nonce, err := base64.StdEncoding.DecodeString(key); err != nil || len(nonce) != 16 {
+		http.Error(w, "invalid websocket key", http.StatusBadRequest)
+		return
+	}
+	select {
+	case <-transport.closed:
+		http.Error(w, "server is closed", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket is not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, buffer, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + _acceptKey(key) + "\r\n\r\n"
+	conn.SetDeadline(time.Time{}) // Clear the deadlines of the HTTP server
+	conn.SetWriteDeadline(time.Now().Add(transport.config.WriteTimeout))
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return
+	}
+
+	ws := &webSocketConn{
+		conn:         conn,
+		reader:       buffer.Reader,
+		readTimeout:  transport.config.ReadTimeout,
+		writeTimeout: transport.config.WriteTimeout,
+		closed:       make(chan struct{}),
+	}
+	select {
+	case transport.accept <- ws:
+		go ws.keepAlive(transport.config.PingInterval)
+	case <-transport.closed:
+		ws.Close()
+	}
+}
+
+func (transport *WebSocketTransport) Accept() (Conn, error) {
+	select {
+	case conn := <-transport.accept:
+		return conn, nil
+	case <-transport.closed:
+		return nil, ErrClosed
+	}
+}
+
+func (transport *WebSocketTransport) Close() error {
+	var err error
+	transport.once.Do(func() {
+		close(transport.closed)
+		if transport.http != nil {
+			err = transport.http.Close()
+		}
+	})
+	return err
+}
+
+func (transport *WebSocketTransport) Addr() string {
+	if transport.listener == nil {
+		return ""
+	}
+	return transport.listener.Addr().String()
+}
+
+func (conn *webSocketConn) Send(data []byte) error {
+	if len(data) > maxWebSocketMessageSize {
+		return ErrMessageTooLarge // The other side would close the connection
+	}
+	select {
+	case <-conn.closed:
+		return ErrClosed
+	default:
+	}
+	return conn.writeFrame(opBinary, data, conn.writeTimeout)
+}
+
+// Read frames until a whole data message, answering the control frames
+func (conn *webSocketConn) Receive() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := conn.readFrame()
+		if err != nil {
+			return nil, conn.fail(err)
+		}
+		switch opcode {
+		case opPing:
+			if err := conn.writeFrame(opPong, payload, conn.writeTimeout); err != nil {
+				return nil, conn.fail(err)
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			conn.shutdown(append([]byte{}, payload[:min(len(payload), 2)]...)) // Echo the status code
+			return nil, ErrClosed
+		case opText, opBinary:
+			if started {
+				return nil, conn.fail(errProtocol) // New message inside a fragmented one
+			}
+			started = true
+		case opContinuation:
+			if !started {
+				return nil, conn.fail(errProtocol)
+			}
+		default:
+			return nil, conn.fail(errProtocol)
+		}
+		if len(message)+len(payload) > maxWebSocketMessageSize {
+			return nil, conn.fail(ErrMessageTooLarge)
+		}
+		message = append(message, payload...)
+		if fin {
+			if message == nil {
+				message = []byte{}
+			}
+			return message, nil
+		}
+	}
+}
+
+func (conn *webSocketConn) Close() error {
+	return conn.shutdown(binary.BigEndian.AppendUint16(nil, closeNormal))
+}
+
+// -- Internal methods -- //
+
+// Ping the other side until the connection is closed
+// A failed ping closes the connection, so Receive returns
+func (conn *webSocketConn) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.writeFrame(opPing, nil, conn.writeTimeout); err != nil {
+				conn.shutdown(nil)
+				return
+			}
+		case <-conn.closed:
+			return
+		}
+	}
+}
+
+// Read a single frame and unmask its payload
+// Fails when no frame starts within the read timeout
+func (conn *webSocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	if conn.readTimeout > 0 {
+		conn.conn.SetReadDeadline(time.Now().Add(conn.readTimeout))
+	}
+	var header [2]byte
+	if _, err = io.ReadFull(conn.reader, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	if header[0]&0x70 != 0 || masked == conn.client {
+		// No extensions are negotiated, the client must mask and the server must not
+		err = errProtocol
+		return
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err = io.ReadFull(conn.reader, extended[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err = io.ReadFull(conn.reader, extended[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if opcode >= opClose && (!fin || length > 125) {
+		err = errProtocol // Control frames are short and not fragmented
+		return
+	}
+	if length > maxWebSocketMessageSize {
+		err = ErrMessageTooLarge
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(conn.reader, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(conn.reader, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// Write a single unfragmented frame within the timeout, masked on the client side
+func (conn *webSocketConn) writeFrame(opcode byte, payload []byte, timeout time.Duration) error {
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|opcode)
+	var maskBit byte
+	if conn.client {
+		maskBit = 0x80
+	}
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	if conn.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		for i := start; i < len(frame); i++ {
+			frame[i] ^= mask[(i-start)%4]
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	conn.sendMutex.Lock()
+	defer conn.sendMutex.Unlock()
+	conn.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err := conn.conn.Write(frame)
+	if errors.Is(err, net.ErrClosed) {
+		return ErrClosed
+	}
+	return err
+}
+
+// Close the connection after a read error, telling the other side why when possible
+func (conn *webSocketConn) fail(err error) error {
+	select {
+	case <-conn.closed:
+		return ErrClosed
+	default:
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		conn.shutdown(nil)
+		return ErrClosed
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		conn.shutdown(nil) // The other side is gone or stuck
+		return err
+	}
+	code := closeProtocolError
+	if errors.Is(err, ErrMessageTooLarge) {
+		code = closeTooLarge
+	}
+	conn.shutdown(binary.BigEndian.AppendUint16(nil, uint16(code)))
+	return err
+}
+
+// Send the close frame with the payload, unless it's nil, and close the connection once
+func (conn *webSocketConn) shutdown(payload []byte) error {
+	var err error
+	conn.once.Do(func() {
+		close(conn.closed)
+		if payload != nil {
+			conn.writeFrame(opClose, payload, closeTimeout)
+		}
+		err = conn.conn.Close()
+	})
+	return err
+}
+
+// Compute the Sec-WebSocket-Accept value for the key
+func _acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Accept the requests without the Origin header and the ones from the pages on the same host
+func _sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, r.Host)
+}
+
+// Check whether a comma-separated header contains the token, case-insensitive
+func _headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}